@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+	"github.com/iOliverNguyen/ml-viz/go/ensemble"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+)
+
+// ensembleTableRows is the number of steps the summary table shows
+const ensembleTableRows = 10
+
+// runEnsemble trains one config once per seed and summarizes the spread of the
+// runs, e.g. "ensemble --phase 2 --case lr-optimal --runs 20 --vary data"
+func runEnsemble(args []string) error {
+	opts := defaultOptions()
+	var configPath string
+	var batchSize int
+	fs := newFlagSet("ensemble", &opts, &configPath)
+	fs.StringVar(&opts.Case, "case", opts.Case, "Start from this case of the phase")
+	fs.IntVar(&opts.Ensemble.Runs, "runs", opts.Ensemble.Runs, "Number of runs")
+	fs.StringVar(&opts.Ensemble.Vary, "vary", opts.Ensemble.Vary, "Randomness the runs vary: data (the data seed) or batch_order (the shuffle seed)")
+	fs.Int64Var(&opts.Ensemble.Seed, "seed", opts.Ensemble.Seed, "Seed of the first run; run k uses seed+k (default 1)")
+	fs.IntVar(&batchSize, "batch-size", 0, "Mini-batch size of every run (overrides the training config)")
+	if _, err := parseOptions(fs, args, &opts, &configPath); err != nil {
+		return err
+	}
+	if err := opts.Ensemble.Validate(); err != nil {
+		return err
+	}
+	if opts.Ensemble.Vary == ensemble.VaryData {
+		if opts.Dataset != nil {
+			return fmt.Errorf("--vary data needs generated data, not a custom dataset")
+		}
+		if opts.Case == "" && opts.Data == nil {
+			return fmt.Errorf("--vary data needs a case or a data config")
+		}
+	}
+
+	// Each run merges its seed into the data or training overrides
+	data, err := overrideMap("data", opts.Data)
+	if err != nil {
+		return err
+	}
+	training, err := overrideMap("training", opts.Training)
+	if err != nil {
+		return err
+	}
+	if batchSize > 0 {
+		training["batch_size"] = batchSize
+	}
+
+	traces := make([]insight.Trace, 0, opts.Ensemble.Runs)
+	for k := 0; k < opts.Ensemble.Runs; k++ {
+		seed := opts.Ensemble.SeedOf(k)
+		fmt.Printf("Training run %d/%d (seed %d)...\n", k+1, opts.Ensemble.Runs, seed)
+		switch opts.Ensemble.Vary {
+		case ensemble.VaryData:
+			data["seed"] = seed
+		case ensemble.VaryBatchOrder:
+			training["shuffle_seed"] = seed
+		}
+		runOpts := opts
+		if len(data) > 0 {
+			if runOpts.Data, err = json.Marshal(data); err != nil {
+				return err
+			}
+		}
+		if runOpts.Training, err = json.Marshal(training); err != nil {
+			return err
+		}
+
+		run, err := trainRun(context.Background(), runOpts)
+		if err != nil {
+			return fmt.Errorf("run %d (seed %d): %w", k+1, seed, err)
+		}
+		if opts.Ensemble.Vary == ensemble.VaryBatchOrder && !batched(run.Snapshots) {
+			return fmt.Errorf("--vary batch_order needs a batch size smaller than the dataset (--batch-size)")
+		}
+		trace, err := core.TraceAnySnapshots(run.Snapshots)
+		if err != nil {
+			return err
+		}
+		traces = append(traces, trace)
+	}
+	result := ensemble.Summarize(opts.Ensemble, traces)
+
+	output := opts.Output
+	if output == "" {
+		output = fmt.Sprintf("output/ensemble-phase%d.json", opts.Phase)
+	}
+	if err := writeJSONFile(output, result); err != nil {
+		return fmt.Errorf("failed to write ensemble: %w", err)
+	}
+	fmt.Printf("Ensemble of %d runs written to %s\n", len(result.Runs), output)
+	if output == "-" {
+		return nil
+	}
+	return printEnsemble(result)
+}
+
+// overrideMap decodes a JSON override section into a map, so single fields can be set
+func overrideMap(section string, data json.RawMessage) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if data == nil {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", section, err)
+	}
+	return m, nil
+}
+
+// batched reports whether the snapshots of any phase were trained on mini-batches
+func batched(snapshots interface{}) bool {
+	switch s := snapshots.(type) {
+	case []core.Snapshot:
+		return len(s) > 0 && s[0].Batch != nil
+	case []linear.LinearSnapshot:
+		return len(s) > 0 && s[0].Batch != nil
+	case []neuron.NeuronSnapshot:
+		return len(s) > 0 && s[0].Batch != nil
+	}
+	return false
+}
+
+// printEnsemble prints the loss quantiles and parameter spread at evenly spaced steps
+func printEnsemble(result ensemble.Result) error {
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	header := []string{"step", "runs", "loss mean", "loss std"}
+	for _, q := range result.Quantiles {
+		header = append(header, fmt.Sprintf("p%g", q*100))
+	}
+	for _, name := range result.ParamNames {
+		header = append(header, name+" std")
+	}
+	if len(result.ParamNames) == 2 {
+		header = append(header, "ellipse radii")
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	every := (len(result.Steps) + ensembleTableRows - 1) / ensembleTableRows
+	for i, s := range result.Steps {
+		if i%max(every, 1) != 0 && i != len(result.Steps)-1 {
+			continue
+		}
+		row := []string{fmt.Sprint(s.Step), fmt.Sprint(s.Runs), fmt.Sprintf("%.6g", s.Loss.Mean), fmt.Sprintf("%.3g", s.Loss.Std)}
+		for _, v := range s.Loss.Quantiles {
+			row = append(row, fmt.Sprintf("%.6g", v))
+		}
+		for _, p := range s.Params {
+			row = append(row, fmt.Sprintf("%.3g", p.Std))
+		}
+		if s.Ellipse != nil {
+			row = append(row, fmt.Sprintf("%.3g, %.3g", s.Ellipse.Radii[0], s.Ellipse.Radii[1]))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+)
+
+// runExport writes the steps and points tables of a snapshots file to the output directory
+func runExport(args []string) error {
+	opts := defaultOptions()
+	opts.Phase = 0
+	opts.Output = "output/export"
+	var configPath string
+	fs := newFlagSet("export", &opts, &configPath)
+	fs.StringVar(&opts.Input, "input", opts.Input, "Snapshots file: a run, a case file or a bare snapshot array")
+	fs.StringVar(&opts.Format, "format", opts.Format, "Export format: csv or columnar")
+	args, err := parseOptions(fs, args, &opts, &configPath)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		opts.Input = args[0]
+	}
+	if opts.Input == "" {
+		return fmt.Errorf("no input file given")
+	}
+
+	snapshots, phase, err := readSnapshotsFile(opts.Input, opts.Phase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exporting Phase %d snapshots from %s to %s...\n", phase, opts.Input, opts.Output)
+	return core.ExportSnapshots(snapshots, opts.Output, opts.Format)
+}
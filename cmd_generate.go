@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+)
+
+// runGenerate writes the case libraries. The output is the directory holding
+// the cases/, cases-phase2/ and cases-phase3/ directories.
+func runGenerate(args []string) error {
+	opts := defaultOptions()
+	opts.Phase = 0
+	opts.Output = "js/public"
+	var configPath string
+	fs := newFlagSet("generate", &opts, &configPath)
+	fs.BoolVar(&opts.Generate.Phase2Snapshots, "phase2-snapshots", opts.Generate.Phase2Snapshots, "Also write Go-trained snapshots.json and loss_grid.json for each Phase 2 case")
+	if _, err := parseOptions(fs, args, &opts, &configPath); err != nil {
+		return err
+	}
+
+	var results *cache.Store
+	if opts.CacheDir != "" {
+		results = cache.NewStore(0, opts.CacheDir)
+	}
+
+	phases := []int{1, 2, 3}
+	if opts.Phase != 0 {
+		phases = []int{opts.Phase}
+	}
+	for _, phase := range phases {
+		if err := generatePhase(phase, opts.Output, results, opts.Generate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generatePhase(phase int, outputRoot string, results *cache.Store, gen GenerateOptions) error {
+	switch phase {
+	case 1:
+		fmt.Println("Generating Phase 1 pre-computed training cases...")
+		if err := core.GenerateCasesCached(filepath.Join(outputRoot, "cases"), results); err != nil {
+			return fmt.Errorf("failed to generate Phase 1 cases: %w", err)
+		}
+	case 2:
+		fmt.Println("Generating Phase 2 pre-computed training cases...")
+		if err := linear.GenerateCases2DCached(filepath.Join(outputRoot, "cases-phase2"), results, gen.Phase2Snapshots); err != nil {
+			return fmt.Errorf("failed to generate Phase 2 cases: %w", err)
+		}
+	case 3:
+		fmt.Println("Generating Phase 3 pre-computed training cases...")
+		if err := neuron.GenerateAllCasesCached(filepath.Join(outputRoot, "cases-phase3"), results); err != nil {
+			return fmt.Errorf("failed to generate Phase 3 cases: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown phase %d", phase)
+	}
+	fmt.Printf("✓ Phase %d cases generated successfully!\n", phase)
+	return nil
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+	"github.com/iOliverNguyen/ml-viz/go/table"
+)
+
+// paramColumn matches the parameter columns of the exported steps table (w, w1, w2, ..., b)
+var paramColumn = regexp.MustCompile(`^(w\d*|b)$`)
+
+// runInspect prints a summary of a snapshots file, or lists the case library
+func runInspect(args []string) error {
+	opts := defaultOptions()
+	opts.Phase = 0
+	var configPath string
+	var listCases bool
+	fs := newFlagSet("inspect", &opts, &configPath)
+	fs.StringVar(&opts.Input, "input", opts.Input, "Snapshots file: a run, a case file or a bare snapshot array")
+	fs.BoolVar(&listCases, "cases", false, "List the cases of --phase (default: all phases)")
+	args, err := parseOptions(fs, args, &opts, &configPath)
+	if err != nil {
+		return err
+	}
+
+	if listCases {
+		return printCases(opts.Phase)
+	}
+	if len(args) > 0 {
+		opts.Input = args[0]
+	}
+	if opts.Input == "" {
+		return fmt.Errorf("no input file given")
+	}
+
+	snapshots, phase, err := readSnapshotsFile(opts.Input, opts.Phase)
+	if err != nil {
+		return err
+	}
+	var trajectory *linear.TrajectoryMetrics
+	var hessian *linear.HessianAnalysis
+	if snaps, ok := snapshots.([]linear.LinearSnapshot); ok {
+		if trajectory, hessian, err = analyzePhase2(opts.Input, snaps); err != nil {
+			return err
+		}
+	}
+	tables, err := core.FlattenSnapshots(snapshots)
+	if err != nil {
+		return err
+	}
+	if err := printSummary(opts.Input, phase, tables.Steps); err != nil {
+		return err
+	}
+	if hessian != nil {
+		if err := printHessian(hessian); err != nil {
+			return err
+		}
+	}
+	if trajectory != nil {
+		if err := printTrajectory(trajectory); err != nil {
+			return err
+		}
+	}
+	observations, err := core.AnalyzeSnapshots(snapshots)
+	if err != nil {
+		return err
+	}
+	return printObservations(observations)
+}
+
+// readSnapshotsFile reads the snapshots of a file. When phase is 0 it is taken
+// from the file's "phase" field (runs), defaulting to 1.
+func readSnapshotsFile(path string, phase int) (interface{}, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if phase == 0 {
+		phase = 1
+		var run struct {
+			Phase string `json:"phase"`
+		}
+		if json.Unmarshal(data, &run) == nil && run.Phase != "" {
+			if phase, err = core.PhaseNumber(run.Phase); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	snapshots, err := core.DecodeSnapshots(phase, data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return snapshots, phase, nil
+}
+
+func printSummary(path string, phase int, steps *table.Table) error {
+	loss := steps.Column("loss").Floats
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "File:\t%s\n", path)
+	fmt.Fprintf(tw, "Phase:\t%d\n", phase)
+	fmt.Fprintf(tw, "Steps:\t%d\n", len(loss))
+	if len(loss) == 0 {
+		return tw.Flush()
+	}
+
+	best := 0
+	for i, l := range loss {
+		if math.IsNaN(l) || math.IsInf(l, 0) {
+			fmt.Fprintf(tw, "Diverged:\tloss is %v at step %d\n", l, steps.Column("step").Ints[i])
+			break
+		}
+		if l < loss[best] {
+			best = i
+		}
+	}
+	first, last := loss[0], loss[len(loss)-1]
+	fmt.Fprintf(tw, "Initial loss:\t%.6g\n", first)
+	fmt.Fprintf(tw, "Final loss:\t%.6g\n", last)
+	fmt.Fprintf(tw, "Best loss:\t%.6g (step %d)\n", loss[best], steps.Column("step").Ints[best])
+	if first != 0 {
+		fmt.Fprintf(tw, "Loss change:\t%+.3g%%\n", (last-first)/first*100)
+	}
+
+	var params []string
+	for _, c := range steps.Columns {
+		if paramColumn.MatchString(c.Name) {
+			params = append(params, fmt.Sprintf("%s=%.6g", c.Name, c.Floats[len(c.Floats)-1]))
+		}
+	}
+	fmt.Fprintf(tw, "Final params:\t%s\n", strings.Join(params, " "))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// In verbose mode, also print every step
+	if verbose {
+		fmt.Fprintln(stdout)
+		tw = tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		for i, c := range steps.Columns {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, c.Name)
+		}
+		fmt.Fprintln(tw)
+		for row := 0; row < steps.NumRows(); row++ {
+			for i, c := range steps.Columns {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				fmt.Fprint(tw, c.Format(row))
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// analyzePhase2 recomputes the trajectory metrics and eigenbasis errors of
+// Phase 2 snapshots, so files written before they existed can be inspected too.
+// The optimum and the Hessian need the file's dataset (runs and Go-trained
+// cases have one); without it the Hessian is nil and the angles to the optimum
+// are left at 0.
+func analyzePhase2(path string, snapshots []linear.LinearSnapshot) (*linear.TrajectoryMetrics, *linear.HessianAnalysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var file struct {
+		Dataset []linear.DataPoint2D `json:"dataset"`
+	}
+	// Bare snapshot arrays have no dataset
+	_ = json.Unmarshal(data, &file)
+
+	linear.AnnotateTrajectory(snapshots, file.Dataset)
+	linear.AnnotateEigenErrors(snapshots, file.Dataset)
+	trajectory := linear.SummarizeTrajectory(snapshots, file.Dataset)
+	if len(file.Dataset) == 0 {
+		return &trajectory, nil, nil
+	}
+	lr := 0.0
+	if len(snapshots) > 0 {
+		lr = snapshots[0].UpdateComponents.LR
+	}
+	hessian := linear.AnalyzeHessian(file.Dataset, lr)
+	return &trajectory, &hessian, nil
+}
+
+func printHessian(h *linear.HessianAnalysis) error {
+	fmt.Fprintln(stdout, "\nHessian:")
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for i, v := range h.Eigenvectors {
+		fmt.Fprintf(tw, "  λ%d:\t%.6g\talong (%.4f, %.4f)", i+1, h.Eigenvalues[i], v[0], v[1])
+		if i < len(h.ErrorFactors) {
+			fmt.Fprintf(tw, "\terror ×%.4g per step", h.ErrorFactors[i])
+		}
+		fmt.Fprintln(tw)
+	}
+	if h.ConditionNumber > 0 {
+		fmt.Fprintf(tw, "  Condition number:\t%.4g\n", h.ConditionNumber)
+	} else {
+		fmt.Fprintf(tw, "  Condition number:\tsingular\n")
+	}
+	fmt.Fprintf(tw, "  Max stable lr:\t%.4g\n", h.MaxStableLR)
+	return tw.Flush()
+}
+
+func printTrajectory(m *linear.TrajectoryMetrics) error {
+	fmt.Fprintln(stdout, "\nTrajectory:")
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	if m.HasOptimum {
+		fmt.Fprintf(tw, "  Optimum:\tw1=%.6g w2=%.6g\n", m.OptimumW1, m.OptimumW2)
+	}
+	fmt.Fprintf(tw, "  Path length:\t%.6g\n", m.PathLength)
+	fmt.Fprintf(tw, "  Path efficiency:\t%.3f\n", m.PathEfficiency)
+	fmt.Fprintf(tw, "  Gradient turn:\tmean %.1f°, max %.1f°\n", degrees(m.MeanGradientTurnAngle), degrees(m.MaxGradientTurnAngle))
+	if m.HasOptimum {
+		fmt.Fprintf(tw, "  Step vs optimum:\tmean %.1f°\n", degrees(m.MeanStepOptimumAngle))
+	}
+	fmt.Fprintf(tw, "  Zigzag steps:\t%.0f%%\n", m.ZigzagFraction*100)
+	fmt.Fprintf(tw, "  Sign flips:\tgrad_w1 %d, grad_w2 %d\n", m.SignFlipsW1, m.SignFlipsW2)
+	return tw.Flush()
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+func printObservations(observations []insight.Observation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+	fmt.Fprintln(stdout, "\nObservations:")
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, o := range observations {
+		steps := fmt.Sprint(o.Step)
+		if o.EndStep != o.Step {
+			steps = fmt.Sprintf("%d-%d", o.Step, o.EndStep)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", steps, o.Kind, o.Message)
+	}
+	return tw.Flush()
+}
+
+func printCases(phase int) error {
+	cases := core.NewCaseLibrary("", nil).List(core.CaseFilter{Phase: phase})
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tID\tCATEGORY\tNAME")
+	for _, c := range cases {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", c.Phase, c.ID, c.Category, c.Name)
+	}
+	return tw.Flush()
+}
+
+// initialLoss returns the loss of the first snapshot of any phase (NaN if there is none)
+func initialLoss(snapshots interface{}) float64 {
+	loss := lossCurve(snapshots)
+	if len(loss) == 0 {
+		return math.NaN()
+	}
+	return loss[0]
+}
+
+// finalLoss returns the loss of the last snapshot of any phase (NaN if there is none)
+func finalLoss(snapshots interface{}) float64 {
+	loss := lossCurve(snapshots)
+	if len(loss) == 0 {
+		return math.NaN()
+	}
+	return loss[len(loss)-1]
+}
+
+// lossCurve returns the loss of every snapshot of any phase
+func lossCurve(snapshots interface{}) []float64 {
+	var loss []float64
+	switch s := snapshots.(type) {
+	case []core.Snapshot:
+		for _, snap := range s {
+			loss = append(loss, snap.Loss)
+		}
+	case []linear.LinearSnapshot:
+		for _, snap := range s {
+			loss = append(loss, snap.Loss)
+		}
+	case []neuron.NeuronSnapshot:
+		for _, snap := range s {
+			loss = append(loss, snap.Loss)
+		}
+	}
+	return loss
+}
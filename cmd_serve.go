@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+)
+
+// runServe starts the HTTP API, serving the frontend from --static-dir or the embedded build
+func runServe(args []string) error {
+	opts := defaultOptions()
+	var configPath string
+	fs := newFlagSet("serve", &opts, &configPath)
+	fs.IntVar(&opts.Server.MaxRuns, "max-runs", opts.Server.MaxRuns, "Maximum number of training runs the server keeps (0 = unlimited)")
+	fs.DurationVar(&opts.Server.RunTTL.Duration, "run-ttl", opts.Server.RunTTL.Duration, "How long the server keeps a training run (0 = forever)")
+	fs.IntVar(&opts.Server.MaxPoints, "max-points", opts.Server.MaxPoints, "Maximum dataset size per training request (0 = unlimited)")
+	fs.IntVar(&opts.Server.MaxSteps, "max-steps", opts.Server.MaxSteps, "Maximum training steps per request (0 = unlimited)")
+	fs.Int64Var(&opts.Server.MaxBodyBytes, "max-body-bytes", opts.Server.MaxBodyBytes, "Maximum request body size in bytes (0 = unlimited)")
+	fs.DurationVar(&opts.Server.RequestTimeout.Duration, "request-timeout", opts.Server.RequestTimeout.Duration, "Deadline for one training request (0 = none)")
+	fs.StringVar(&opts.Server.StaticDir, "static-dir", opts.Server.StaticDir, "Serve the built frontend from this directory instead of the embedded copy (e.g. js/dist)")
+	if _, err := parseOptions(fs, args, &opts, &configPath); err != nil {
+		return err
+	}
+
+	serverConfig := core.DefaultServerConfig()
+	if opts.Output != "" {
+		serverConfig.SnapshotsPath = opts.Output
+	}
+	serverConfig.Retention = core.RunRetention{MaxRuns: opts.Server.MaxRuns, TTL: opts.Server.RunTTL.Duration}
+	serverConfig.CacheDir = opts.CacheDir
+	serverConfig.Limits.MaxPoints = opts.Server.MaxPoints
+	serverConfig.Limits.MaxSteps = opts.Server.MaxSteps
+	serverConfig.Limits.MaxBodyBytes = opts.Server.MaxBodyBytes
+	serverConfig.Limits.RequestTimeout = opts.Server.RequestTimeout.Duration
+	if opts.Server.StaticDir != "" {
+		serverConfig.StaticFS = os.DirFS(opts.Server.StaticDir)
+	} else if assets, ok := uiAssets(); ok {
+		serverConfig.StaticFS = assets
+	}
+	debugJSON("Server options", opts.Server)
+
+	fmt.Println("Starting HTTP server...")
+	if err := core.ListenAndServe(opts.Addr, serverConfig); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
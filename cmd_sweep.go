@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// SweepResult is the outcome of one value of a sweep
+type SweepResult struct {
+	Value       interface{} `json:"value"`
+	NumSteps    int         `json:"num_steps"`
+	InitialLoss float64     `json:"initial_loss"`
+	FinalLoss   float64     `json:"final_loss"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// runSweep trains once per value of one training parameter, e.g.
+// "sweep --phase 2 --case lr-optimal --param lr --values 0.001,0.01,0.05"
+func runSweep(args []string) error {
+	opts := defaultOptions()
+	var configPath, values string
+	fs := newFlagSet("sweep", &opts, &configPath)
+	fs.StringVar(&opts.Case, "case", opts.Case, "Start from this case of the phase")
+	fs.StringVar(&opts.Sweep.Param, "param", opts.Sweep.Param, "Training config field to vary, by its JSON name (e.g. lr, max_steps, activation)")
+	fs.StringVar(&values, "values", "", "Comma-separated values of the parameter")
+	if _, err := parseOptions(fs, args, &opts, &configPath); err != nil {
+		return err
+	}
+	if values != "" {
+		opts.Sweep.Values = parseSweepValues(values)
+	}
+	if opts.Sweep.Param == "" || len(opts.Sweep.Values) == 0 {
+		return fmt.Errorf("--param and --values are required")
+	}
+
+	// Each value is merged into the training overrides as {"<param>": value}
+	var training map[string]interface{}
+	if opts.Training != nil {
+		if err := json.Unmarshal(opts.Training, &training); err != nil {
+			return fmt.Errorf("invalid training: %w", err)
+		}
+	}
+	if training == nil {
+		training = map[string]interface{}{}
+	}
+
+	results := make([]SweepResult, 0, len(opts.Sweep.Values))
+	for _, value := range opts.Sweep.Values {
+		fmt.Printf("Training with %s = %v...\n", opts.Sweep.Param, value)
+		training[opts.Sweep.Param] = value
+		runOpts := opts
+		var err error
+		if runOpts.Training, err = json.Marshal(training); err != nil {
+			return err
+		}
+
+		result := SweepResult{Value: value}
+		run, err := trainRun(context.Background(), runOpts)
+		if err != nil {
+			// A failing value (e.g. an invalid activation) is reported, not fatal
+			result.Error = err.Error()
+		} else {
+			result.NumSteps = run.NumSteps
+			result.InitialLoss = initialLoss(run.Snapshots)
+			result.FinalLoss = finalLoss(run.Snapshots)
+		}
+		results = append(results, result)
+	}
+
+	if opts.Output != "" {
+		if err := writeJSONFile(opts.Output, results); err != nil {
+			return fmt.Errorf("failed to write sweep results: %w", err)
+		}
+		fmt.Printf("Sweep results written to %s\n", opts.Output)
+		if opts.Output == "-" {
+			return nil
+		}
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tsteps\tinitial loss\tfinal loss\n", opts.Sweep.Param)
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(tw, "%v\t-\t-\t-\terror: %s\n", r.Value, r.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%v\t%d\t%.6g\t%.6g\n", r.Value, r.NumSteps, r.InitialLoss, r.FinalLoss)
+	}
+	return tw.Flush()
+}
+
+// parseSweepValues parses comma-separated values as JSON, falling back to strings (e.g. relu)
+func parseSweepValues(s string) []interface{} {
+	var values []interface{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		var v interface{}
+		if err := json.Unmarshal([]byte(part), &v); err != nil {
+			v = part
+		}
+		values = append(values, v)
+	}
+	return values
+}
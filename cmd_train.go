@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+)
+
+// runTrain trains one run. Phase 1 writes the bare snapshot array the frontend
+// and /api/snapshots read; Phases 2 and 3 write the run with its dataset.
+func runTrain(args []string) error {
+	opts := defaultOptions()
+	var configPath string
+	fs := newFlagSet("train", &opts, &configPath)
+	fs.StringVar(&opts.Case, "case", opts.Case, "Start from this case of the phase (see 'inspect --cases')")
+	if _, err := parseOptions(fs, args, &opts, &configPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Running Phase %d training...\n", opts.Phase)
+	run, err := trainRun(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = "output/snapshots.json"
+		if opts.Phase != 1 {
+			output = fmt.Sprintf("output/run-phase%d.json", opts.Phase)
+		}
+	}
+	var result interface{} = run
+	if opts.Phase == 1 {
+		result = run.Snapshots
+	}
+	if err := writeJSONFile(output, result); err != nil {
+		return fmt.Errorf("failed to write snapshots: %w", err)
+	}
+
+	fmt.Printf("\nSnapshots written to %s (%d steps, final loss %.6g)\n", output, run.NumSteps, finalLoss(run.Snapshots))
+	fmt.Println("\nVisualization options:")
+	fmt.Println("  1. Server mode: go run . serve")
+	fmt.Println("  2. Batch mode:  cp output/snapshots.json js/public/")
+	return nil
+}
+
+// trainRun trains one run of opts.Phase. It starts from opts.Case (or the
+// phase's defaults), then applies the data, dataset and training overrides.
+func trainRun(ctx context.Context, opts Options) (*core.Run, error) {
+	switch opts.Phase {
+	case 1:
+		return trainPhase1(ctx, opts)
+	case 2:
+		return trainPhase2(ctx, opts)
+	case 3:
+		return trainPhase3(ctx, opts)
+	}
+	return nil, fmt.Errorf("unknown phase %d", opts.Phase)
+}
+
+func trainPhase1(ctx context.Context, opts Options) (*core.Run, error) {
+	data := core.GetDataset()
+	config := core.DefaultTrainingConfig()
+	var dataConfig *core.DataGenConfig
+	if opts.Case != "" {
+		c, err := findCase1(opts.Case)
+		if err != nil {
+			return nil, err
+		}
+		dataConfig, config = &c.DataConfig, c.Training
+	}
+
+	if opts.Data != nil {
+		if dataConfig == nil {
+			dataConfig = &core.DataGenConfig{}
+		}
+		if err := overlay("data", opts.Data, dataConfig); err != nil {
+			return nil, err
+		}
+	}
+	if dataConfig != nil {
+		var err error
+		if data, err = core.GenerateRandomData(*dataConfig); err != nil {
+			return nil, fmt.Errorf("failed to generate data: %w", err)
+		}
+	}
+	if opts.Dataset != nil {
+		data = nil
+		if err := overlay("dataset", opts.Dataset, &data); err != nil {
+			return nil, err
+		}
+	}
+	if err := overlay("training", opts.Training, &config); err != nil {
+		return nil, err
+	}
+	if err := core.ValidateDataset(data); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+	// Same checks as the API
+	if err := config.LineSearch.Validate(true); err != nil {
+		return nil, fmt.Errorf("invalid training config: %w", err)
+	}
+	if err := minibatch.Validate(config.BatchSize, config.LineSearch.Active()); err != nil {
+		return nil, fmt.Errorf("invalid training config: %w", err)
+	}
+	debugJSON("Training config", config)
+
+	snapshots, err := core.RunTrainingWithDatasetContext(ctx, data, config)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Run{Phase: "phase1", NumSteps: len(snapshots), Dataset: data, Snapshots: snapshots}, nil
+}
+
+func trainPhase2(ctx context.Context, opts Options) (*core.Run, error) {
+	var data []linear.DataPoint2D
+	var dataConfig *linear.DataGenConfig2D
+	var config linear.TrainingConfig2D
+	if opts.Case != "" {
+		c, err := findCase2(opts.Case)
+		if err != nil {
+			return nil, err
+		}
+		dataConfig, config = &c.DataConfig, c.TrainConfig
+	}
+
+	if opts.Data != nil {
+		if dataConfig == nil {
+			dataConfig = &linear.DataGenConfig2D{}
+		}
+		if err := overlay("data", opts.Data, dataConfig); err != nil {
+			return nil, err
+		}
+	}
+	if dataConfig != nil {
+		data = linear.GenerateRandomData(*dataConfig)
+	}
+	if opts.Dataset != nil {
+		data = nil
+		if err := overlay("dataset", opts.Dataset, &data); err != nil {
+			return nil, err
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("phase 2 needs a case, data or dataset")
+	}
+	if err := overlay("training", opts.Training, &config); err != nil {
+		return nil, err
+	}
+	if err := linear.ValidateTrainingConfig2D(config); err != nil {
+		return nil, fmt.Errorf("invalid training config: %w", err)
+	}
+	// Without bounds, the loss grid is fitted to the trajectory like the API's
+	grid := linear.LossGridConfig{Resolution: linear.DefaultLossGridConfig().Resolution}
+	if err := overlay("loss_grid", opts.LossGrid, &grid); err != nil {
+		return nil, err
+	}
+	if err := linear.ValidateLossGridConfig(grid); err != nil {
+		return nil, fmt.Errorf("invalid loss_grid: %w", err)
+	}
+	if err := linear.ValidateDataset(data); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+	debugJSON("Training config", config)
+	debugJSON("Loss grid", grid)
+
+	snapshots, err := linear.RunTrainingContext(ctx, data, config)
+	if err != nil {
+		return nil, err
+	}
+	if grid.AutoBounds() {
+		fitted := linear.FitLossGridConfig(data, grid.Resolution, snapshots)
+		fitted.Refine = grid.Refine
+		grid = fitted
+	}
+	lossGrid := linear.BuildLossGrid(data, grid)
+	hessian := linear.AnalyzeHessian(data, config.LR)
+	trajectory := linear.SummarizeTrajectory(snapshots, data)
+	return &core.Run{Phase: "phase2", NumSteps: len(snapshots), Dataset: data, LossGrid: &lossGrid, Hessian: &hessian, Trajectory: &trajectory, Snapshots: snapshots}, nil
+}
+
+func trainPhase3(ctx context.Context, opts Options) (*core.Run, error) {
+	var data []neuron.DataPoint2DNeuron
+	var dataConfig *neuron.DataGenConfig
+	var initParams neuron.NeuronParams
+	var config neuron.TrainingConfig
+	if opts.Case != "" {
+		spec, err := findCase3(opts.Case)
+		if err != nil {
+			return nil, err
+		}
+		dataConfig, initParams, config = &spec.DataConfig, spec.InitParams, spec.Training
+	}
+
+	if opts.Data != nil {
+		if dataConfig == nil {
+			dataConfig = &neuron.DataGenConfig{}
+		}
+		if err := overlay("data", opts.Data, dataConfig); err != nil {
+			return nil, err
+		}
+	}
+	if dataConfig != nil {
+		var err error
+		if data, err = neuron.GenerateDataset(*dataConfig); err != nil {
+			return nil, fmt.Errorf("failed to generate data: %w", err)
+		}
+	}
+	if opts.Dataset != nil {
+		data = nil
+		if err := overlay("dataset", opts.Dataset, &data); err != nil {
+			return nil, err
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("phase 3 needs a case, data or dataset")
+	}
+	if err := overlay("init_params", opts.InitParams, &initParams); err != nil {
+		return nil, err
+	}
+	if err := overlay("training", opts.Training, &config); err != nil {
+		return nil, err
+	}
+
+	// Same defaults as the API
+	if config.Activation == "" {
+		config.Activation = "sigmoid"
+	}
+	if err := neuron.ValidateActivation(config); err != nil {
+		return nil, err
+	}
+	if err := config.LineSearch.Validate(false); err != nil {
+		return nil, fmt.Errorf("invalid training config: %w", err)
+	}
+	if err := minibatch.Validate(config.BatchSize, config.LineSearch.Active()); err != nil {
+		return nil, fmt.Errorf("invalid training config: %w", err)
+	}
+	if initParams.W == nil {
+		initParams.W = make([]float64, 2)
+	}
+	if err := neuron.ValidateDataset(data, len(initParams.W)); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+	debugJSON("Init params", initParams)
+	debugJSON("Training config", config)
+
+	snapshots, err := neuron.TrainContext(ctx, data, initParams, config)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Run{Phase: "phase3", NumSteps: len(snapshots), Dataset: data, Snapshots: snapshots}, nil
+}
+
+// overlay decodes the JSON override of a config section onto v; fields it does not set keep their value
+func overlay(section string, data json.RawMessage, v interface{}) error {
+	if data == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s: %w", section, err)
+	}
+	return nil
+}
+
+func findCase1(id string) (core.CaseConfig, error) {
+	for _, c := range core.Cases() {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return core.CaseConfig{}, fmt.Errorf("%w: phase 1, id %q", core.ErrCaseNotFound, id)
+}
+
+func findCase2(id string) (linear.CaseConfig2D, error) {
+	for _, c := range linear.Cases2D() {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return linear.CaseConfig2D{}, fmt.Errorf("%w: phase 2, id %q", core.ErrCaseNotFound, id)
+}
+
+func findCase3(id string) (neuron.CaseSpec, error) {
+	for _, c := range neuron.Cases() {
+		if c.CaseID == id {
+			return c, nil
+		}
+	}
+	return neuron.CaseSpec{}, fmt.Errorf("%w: phase 3, id %q", core.ErrCaseNotFound, id)
+}
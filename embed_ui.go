@@ -0,0 +1,23 @@
+//go:build embedui
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Built frontend (cd js && pnpm build). Vite copies js/public into dist,
+// so the case directories and content JSON are embedded along with the app.
+//
+//go:embed all:js/dist
+var uiFS embed.FS
+
+// uiAssets returns the embedded frontend assets
+func uiAssets() (fs.FS, bool) {
+	sub, err := fs.Sub(uiFS, "js/dist")
+	if err != nil {
+		return nil, false
+	}
+	return sub, true
+}
@@ -0,0 +1,10 @@
+//go:build !embedui
+
+package main
+
+import "io/fs"
+
+// uiAssets reports that no frontend is embedded. Build with -tags embedui to embed js/dist.
+func uiAssets() (fs.FS, bool) {
+	return nil, false
+}
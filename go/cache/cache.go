@@ -0,0 +1,196 @@
+// Package cache stores training results by a content hash of their inputs, so
+// identical requests (same phase, data, and configs) are computed only once.
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FormatVersion is mixed into every key. Bump it when a snapshot format changes
+// so stale results on disk are never served.
+const FormatVersion = "7"
+
+// KeyInput identifies a training result. Random-data requests set DataConfig,
+// custom-data requests set Dataset.
+type KeyInput struct {
+	Phase          string      `json:"phase"`
+	Dataset        interface{} `json:"dataset,omitempty"`
+	DataConfig     interface{} `json:"data_config,omitempty"`
+	TrainingConfig interface{} `json:"training_config"`
+	Extra          interface{} `json:"extra,omitempty"` // anything else the result depends on (init params, loss grid, ...)
+}
+
+// Key returns the canonical hash of the inputs: hex SHA-256 of their JSON encoding.
+// encoding/json writes struct fields in declaration order and sorts map keys,
+// so equal inputs always hash equally.
+func Key(in KeyInput) (string, error) {
+	data, err := json.Marshal(struct {
+		Version string   `json:"version"`
+		Input   KeyInput `json:"input"`
+	}{FormatVersion, in})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Store is an in-memory LRU of JSON results, optionally backed by a directory on disk
+type Store struct {
+	mu       sync.Mutex
+	capacity int
+	dir      string
+	order    *list.List               // most recently used at the front
+	items    map[string]*list.Element // key -> element holding *entry
+}
+
+type entry struct {
+	key  string
+	data []byte
+}
+
+// NewStore creates a store keeping up to capacity results in memory (0 = unlimited).
+// When dir is non-empty, results are also written there and survive restarts.
+func NewStore(capacity int, dir string) *Store {
+	return &Store{
+		capacity: capacity,
+		dir:      dir,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the result for key from memory, falling back to disk
+func (s *Store) Get(key string) ([]byte, bool) {
+	if s == nil || key == "" {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	if elem, ok := s.items[key]; ok {
+		s.order.MoveToFront(elem)
+		data := elem.Value.(*entry).data
+		s.mu.Unlock()
+		return data, true
+	}
+	s.mu.Unlock()
+
+	if s.dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	s.addLocked(key, data)
+	s.mu.Unlock()
+	return data, true
+}
+
+// Put stores the result for key in memory and, if configured, on disk
+func (s *Store) Put(key string, data []byte) error {
+	if s == nil || key == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.addLocked(key, data)
+	s.mu.Unlock()
+
+	if s.dir == "" {
+		return nil
+	}
+
+	// Write to a temp file and rename so readers never see partial results
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// GetOrCompute returns the cached result for key, or calls compute, stores its
+// JSON encoding and returns that. hit reports whether the result was cached.
+// An empty key disables caching.
+func (s *Store) GetOrCompute(key string, compute func() (interface{}, error)) (data []byte, hit bool, err error) {
+	if data, ok := s.Get(key); ok {
+		return data, true, nil
+	}
+
+	result, err := compute()
+	if err != nil {
+		return nil, false, err
+	}
+	data, err = json.Marshal(result)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.Put(key, data); err != nil {
+		return nil, false, err
+	}
+	return data, false, nil
+}
+
+// Len returns the number of results held in memory
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// addLocked inserts or refreshes key and evicts the least recently used results. Caller must hold s.mu.
+func (s *Store) addLocked(key string, data []byte) {
+	if elem, ok := s.items[key]; ok {
+		elem.Value.(*entry).data = data
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&entry{key: key, data: data})
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*entry).key)
+	}
+}
+
+// path returns the on-disk location of key, sharded by its first two hex digits
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key+".json")
+}
+
+// WriteIndented writes a cached JSON result to path in the indented layout the
+// case generators use
+func WriteIndented(path string, data []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
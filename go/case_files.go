@@ -0,0 +1,74 @@
+package core
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/iOliverNguyen/ml-viz/go/casefile"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+)
+
+// builtinCaseFiles holds the Phase 1 case library, one file per case
+//
+//go:embed cases/*.yaml
+var builtinCaseFiles embed.FS
+
+var caseLibrary = casefile.MustLoadLibrary(builtinCaseFiles, "cases",
+	func(c CaseConfig) string { return c.ID },
+	func(c *CaseConfig) error { return ValidateCase(*c) })
+
+// Cases returns the Phase 1 case library: the built-in cases plus those added with AddCaseFiles
+func Cases() []CaseConfig {
+	return caseLibrary.Cases()
+}
+
+// LoadCases decodes and validates Phase 1 case files
+func LoadCases(files []casefile.File) ([]CaseConfig, error) {
+	return caseLibrary.Load(files)
+}
+
+// AddCaseFiles loads the case files of dir into the library. A case with the
+// ID of an existing case replaces it.
+func AddCaseFiles(dir string) error {
+	return caseLibrary.AddDir(dir)
+}
+
+// ValidateCase checks that a case is complete and reproducible
+func ValidateCase(c CaseConfig) error {
+	if err := casefile.ValidateID(c.ID); err != nil {
+		return err
+	}
+	if c.Name == "" || c.Category == "" {
+		return fmt.Errorf("case %s: name and category are required", c.ID)
+	}
+	if c.DataConfig.Seed == 0 {
+		return fmt.Errorf("case %s: data_config.seed must be set so the case is reproducible", c.ID)
+	}
+	if _, err := GenerateRandomData(c.DataConfig); err != nil {
+		return fmt.Errorf("case %s: data_config: %w", c.ID, err)
+	}
+	if err := validateTraining(c.Training); err != nil {
+		return fmt.Errorf("case %s: training_config: %w", c.ID, err)
+	}
+	if err := c.Race.Validate(); err != nil {
+		return fmt.Errorf("case %s: race: %w", c.ID, err)
+	}
+	if err := c.Expect.Validate(); err != nil {
+		return fmt.Errorf("case %s: %w", c.ID, err)
+	}
+	if c.Expect.HasNeuronClaims() {
+		return fmt.Errorf("case %s: saturation_fraction and dead_relu_fraction only apply to Phase 3", c.ID)
+	}
+	return nil
+}
+
+// validateTraining checks the training config of a case or race run
+func validateTraining(config TrainingConfig) error {
+	if config.LR <= 0 || config.Steps <= 0 {
+		return fmt.Errorf("needs a positive lr and steps")
+	}
+	if err := config.LineSearch.Validate(true); err != nil {
+		return err
+	}
+	return minibatch.Validate(config.BatchSize, config.LineSearch.Active())
+}
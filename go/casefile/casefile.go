@@ -0,0 +1,101 @@
+// Package casefile reads declarative case definitions. A case file is one JSON
+// or YAML document; YAML is converted to JSON so each phase decodes its cases
+// with the json tags of its own config types.
+package casefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is one case file, converted to JSON
+type File struct {
+	Name string // path of the file, for error messages
+	JSON []byte
+}
+
+// Decode strictly decodes the file into v: unknown fields are errors, so typos
+// in hand-written files are caught
+func (f File) Decode(v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(f.JSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// ReadDir reads the .json, .yaml and .yml files of dir in fsys, ordered by
+// file name (prefix names with a number to order cases)
+func ReadDir(fsys fs.FS, dir string) ([]File, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var files []File
+	for _, entry := range entries {
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		name := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if ext != ".json" {
+			if data, err = yamlToJSON(data); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		files = append(files, File{Name: name, JSON: data})
+	}
+	return files, nil
+}
+
+// ReadOSDir reads the case files of a directory on disk. A missing directory has no cases.
+func ReadOSDir(dir string) ([]File, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	files, err := ReadDir(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	for i := range files {
+		files[i].Name = filepath.Join(dir, files[i].Name)
+	}
+	return files, nil
+}
+
+// validID matches case IDs, which name the case's output directory
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ValidateID checks that a case ID is lowercase letters, digits and dashes
+func ValidateID(id string) error {
+	if !validID.MatchString(id) {
+		return fmt.Errorf("invalid case id %q: use lowercase letters, digits and dashes", id)
+	}
+	return nil
+}
+
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,88 @@
+package casefile
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// Library is the case library of one phase: its built-in cases plus those
+// added from case directories. C is the phase's case type.
+type Library[C any] struct {
+	id       func(C) string
+	validate func(*C) error
+
+	mu    sync.RWMutex
+	cases []C
+}
+
+// MustLoadLibrary loads the built-in case files of dir in fsys into a new
+// library and panics if they are invalid. id returns a case's ID; validate
+// checks a decoded case and may fill in defaults.
+func MustLoadLibrary[C any](fsys fs.FS, dir string, id func(C) string, validate func(*C) error) *Library[C] {
+	l := &Library[C]{id: id, validate: validate}
+	files, err := ReadDir(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	if l.cases, err = l.Load(files); err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Cases returns a copy of the library's cases, in load order
+func (l *Library[C]) Cases() []C {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]C(nil), l.cases...)
+}
+
+// Load decodes and validates case files without adding them to the library
+func (l *Library[C]) Load(files []File) ([]C, error) {
+	var cases []C
+	seen := map[string]string{}
+	for _, f := range files {
+		var c C
+		if err := f.Decode(&c); err != nil {
+			return nil, err
+		}
+		if err := l.validate(&c); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if other, ok := seen[l.id(c)]; ok {
+			return nil, fmt.Errorf("%s: case id %q is already defined in %s", f.Name, l.id(c), other)
+		}
+		seen[l.id(c)] = f.Name
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// AddDir loads the case files of dir into the library. A case with the ID of
+// an existing case replaces it.
+func (l *Library[C]) AddDir(dir string) error {
+	files, err := ReadOSDir(dir)
+	if err != nil {
+		return err
+	}
+	added, err := l.Load(files)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range added {
+		replaced := false
+		for i := range l.cases {
+			if l.id(l.cases[i]) == l.id(c) {
+				l.cases[i], replaced = c, true
+			}
+		}
+		if !replaced {
+			l.cases = append(l.cases, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/expect"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+)
+
+// ErrCaseNotFound is returned for an unknown phase or case ID
+var ErrCaseNotFound = errors.New("case not found")
+
+// ErrNoRace is returned for the race of a case that declares none
+var ErrNoRace = errors.New("case has no race")
+
+// caseDirs maps each phase to its case directory under the public assets
+var caseDirs = map[int]string{
+	1: "cases",
+	2: "cases-phase2",
+	3: "cases-phase3",
+}
+
+// CaseSummary is one entry of the case listing across all phases
+type CaseSummary struct {
+	Phase       int      `json:"phase"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Emoji       string   `json:"emoji"`
+	Category    string   `json:"category"`
+	Activation  string   `json:"activation,omitempty"` // Phase 3 only
+	Insights    []string `json:"insights,omitempty"`
+
+	Expect *expect.Expectations `json:"expect,omitempty"`
+}
+
+// CaseFilter selects cases from the library. Zero fields match everything.
+type CaseFilter struct {
+	Phase      int
+	Category   string
+	Activation string
+}
+
+func (f CaseFilter) matches(c CaseSummary) bool {
+	return (f.Phase == 0 || f.Phase == c.Phase) &&
+		(f.Category == "" || f.Category == c.Category) &&
+		(f.Activation == "" || f.Activation == c.Activation)
+}
+
+// CaseLibrary serves the case libraries of all phases. Snapshots are read from
+// the pre-computed case directories when present, otherwise generated on demand
+// and kept in the result cache.
+type CaseLibrary struct {
+	dir     string // directory holding cases/, cases-phase2/ and cases-phase3/ ("" = always generate)
+	results *cache.Store
+}
+
+// NewCaseLibrary creates a case library reading pre-computed cases from dir and
+// caching generated ones in results
+func NewCaseLibrary(dir string, results *cache.Store) *CaseLibrary {
+	return &CaseLibrary{
+		dir:     dir,
+		results: results,
+	}
+}
+
+// List returns the cases of all phases matching the filter
+func (l *CaseLibrary) List(filter CaseFilter) []CaseSummary {
+	all := []CaseSummary{}
+	for _, c := range Cases() {
+		all = append(all, CaseSummary{
+			Phase:       1,
+			ID:          c.ID,
+			Name:        c.Name,
+			Description: c.Description,
+			Emoji:       c.Emoji,
+			Category:    c.Category,
+			Insights:    c.Insights,
+			Expect:      c.Expect,
+		})
+	}
+	for _, c := range linear.Cases2D() {
+		all = append(all, CaseSummary{
+			Phase:       2,
+			ID:          c.ID,
+			Name:        c.Name,
+			Description: c.Description,
+			Emoji:       c.Emoji,
+			Category:    c.Category,
+			Insights:    c.Insights,
+			Expect:      c.Expect,
+		})
+	}
+	for _, c := range neuron.Cases() {
+		all = append(all, CaseSummary{
+			Phase:       3,
+			ID:          c.CaseID,
+			Name:        c.Name,
+			Description: c.Description,
+			Emoji:       c.Emoji,
+			Category:    c.Category,
+			Activation:  c.Activation,
+			Insights:    c.Insights,
+			Expect:      c.Expect,
+		})
+	}
+
+	matched := []CaseSummary{}
+	for _, c := range all {
+		if filter.matches(c) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// Config returns the full definition of a case
+func (l *CaseLibrary) Config(phase int, id string) (interface{}, error) {
+	switch phase {
+	case 1:
+		for _, c := range Cases() {
+			if c.ID == id {
+				return c, nil
+			}
+		}
+	case 2:
+		for _, c := range linear.Cases2D() {
+			if c.ID == id {
+				return linear.CaseConfigFile(c), nil
+			}
+		}
+	case 3:
+		for _, c := range neuron.Cases() {
+			if c.CaseID == id {
+				return c, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: phase %d, id %q", ErrCaseNotFound, phase, id)
+}
+
+// Snapshots returns the JSON snapshots of a case and their cache key. Cases
+// without a pre-computed file are generated and cached.
+func (l *CaseLibrary) Snapshots(phase int, id string) (data []byte, key string, err error) {
+	key, generate, err := caseGenerator(phase, id)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err = l.precomputed(phase, id, "snapshots.json", key, generate)
+	return data, key, err
+}
+
+// Race returns the JSON race of a comparison case and its cache key, like Snapshots
+func (l *CaseLibrary) Race(phase int, id string) (data []byte, key string, err error) {
+	key, generate, err := raceGenerator(phase, id)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err = l.precomputed(phase, id, "race.json", key, generate)
+	return data, key, err
+}
+
+// precomputed reads a file written by the case generators, or generates and caches its content
+func (l *CaseLibrary) precomputed(phase int, id, file, key string, generate func() (interface{}, error)) ([]byte, error) {
+	if l.dir != "" {
+		path := filepath.Join(l.dir, caseDirs[phase], id, file)
+		if data, err := os.ReadFile(path); err == nil {
+			return data, nil
+		}
+	}
+	data, _, err := l.results.GetOrCompute(key, generate)
+	return data, err
+}
+
+// caseGenerator returns the cache key of a case and a function training it in
+// the same format its generator writes
+func caseGenerator(phase int, id string) (key string, generate func() (interface{}, error), err error) {
+	switch phase {
+	case 1:
+		for _, c := range Cases() {
+			if c.ID == id {
+				key, err := CaseKey(c)
+				return key, func() (interface{}, error) { return GenerateCaseSnapshots(c) }, err
+			}
+		}
+	case 2:
+		for _, c := range linear.Cases2D() {
+			if c.ID == id {
+				key, err := linear.CaseKey2D(c)
+				return key, func() (interface{}, error) { return linear.GenerateCaseSnapshots2D(c) }, err
+			}
+		}
+	case 3:
+		for _, c := range neuron.Cases() {
+			if c.CaseID == id {
+				key, err := neuron.CaseKey(c)
+				return key, func() (interface{}, error) { return neuron.GenerateCase(c) }, err
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("%w: phase %d, id %q", ErrCaseNotFound, phase, id)
+}
+
+// raceGenerator returns the cache key of a case's race and a function running it
+func raceGenerator(phase int, id string) (key string, generate func() (interface{}, error), err error) {
+	noRace := fmt.Errorf("%w: phase %d, id %q", ErrNoRace, phase, id)
+	switch phase {
+	case 1:
+		for _, c := range Cases() {
+			if c.ID == id {
+				if c.Race == nil {
+					return "", nil, noRace
+				}
+				key, err := RaceKey(c)
+				return key, func() (interface{}, error) { return RunRace(c) }, err
+			}
+		}
+	case 2:
+		for _, c := range linear.Cases2D() {
+			if c.ID == id {
+				if c.Race == nil {
+					return "", nil, noRace
+				}
+				key, err := linear.RaceKey2D(c)
+				return key, func() (interface{}, error) { return linear.RunRace2D(c) }, err
+			}
+		}
+	case 3:
+		for _, c := range neuron.Cases() {
+			if c.CaseID == id {
+				if c.Race == nil {
+					return "", nil, noRace
+				}
+				key, err := neuron.RaceKey(c)
+				return key, func() (interface{}, error) { return neuron.RunRace(c) }, err
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("%w: phase %d, id %q", ErrCaseNotFound, phase, id)
+}
@@ -0,0 +1,247 @@
+// Package ensemble trains one config across many seeds, varying a single
+// source of randomness, and summarizes how the runs spread step by step. It
+// works on the phase-independent traces of the insight package, so it serves
+// every phase.
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+)
+
+// Sources of randomness of Config.Vary
+const (
+	VaryData       = "data"        // redraw the dataset's noise (the data seed)
+	VaryBatchOrder = "batch_order" // reshuffle the mini-batches (the shuffle seed); needs a batch size
+)
+
+// MaxRuns bounds Config.Runs
+const MaxRuns = 1000
+
+// DefaultQuantiles are the loss and parameter quantiles of a summary
+var DefaultQuantiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+
+// Config chooses the runs of an ensemble: run k uses seed Seed+k
+type Config struct {
+	Runs      int       `json:"runs"`
+	Vary      string    `json:"vary"`
+	Seed      int64     `json:"seed,omitempty"`      // default 1
+	Quantiles []float64 `json:"quantiles,omitempty"` // default DefaultQuantiles
+}
+
+// Validate checks the number of runs, the source of randomness and the quantiles
+func (c Config) Validate() error {
+	if c.Runs < 2 || c.Runs > MaxRuns {
+		return fmt.Errorf("an ensemble needs between 2 and %d runs, got %d", MaxRuns, c.Runs)
+	}
+	switch c.Vary {
+	case VaryData, VaryBatchOrder:
+	default:
+		return fmt.Errorf("unknown ensemble vary %q (want %s or %s)", c.Vary, VaryData, VaryBatchOrder)
+	}
+	for _, q := range c.Quantiles {
+		if !(q >= 0 && q <= 1) {
+			return fmt.Errorf("quantiles must be in [0, 1], got %g", q)
+		}
+	}
+	return nil
+}
+
+// SeedOf returns the seed of run k
+func (c Config) SeedOf(k int) int64 {
+	if c.Seed == 0 {
+		return 1 + int64(k)
+	}
+	return c.Seed + int64(k)
+}
+
+func (c Config) quantiles() []float64 {
+	if len(c.Quantiles) == 0 {
+		return DefaultQuantiles
+	}
+	q := append([]float64(nil), c.Quantiles...)
+	sort.Float64s(q)
+	return q
+}
+
+// Trajectory is one run of an ensemble: its loss and parameters at every step
+type Trajectory struct {
+	Seed   int64       `json:"seed"`
+	Losses []float64   `json:"losses"`
+	Params [][]float64 `json:"params"` // per step, in the order of Result.ParamNames
+}
+
+// Summary describes the spread of one value across the runs
+type Summary struct {
+	Mean      float64   `json:"mean"`
+	Std       float64   `json:"std"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Quantiles []float64 `json:"quantiles"` // at Result.Quantiles
+}
+
+// Ellipse is the one-standard-deviation ellipse of a 2D parameter cloud: its
+// center, and its principal axes (unit vectors, major first) with the standard
+// deviation along each
+type Ellipse struct {
+	Center [2]float64    `json:"center"`
+	Axes   [2][2]float64 `json:"axes"`
+	Radii  [2]float64    `json:"radii"`
+	Angle  float64       `json:"angle"` // of the major axis from the first parameter's axis, in radians
+}
+
+// Step summarizes the runs at one training step. Runs that diverged (a
+// non-finite loss or parameter) drop out of the summaries from then on.
+type Step struct {
+	Step       int         `json:"step"`
+	Runs       int         `json:"runs"` // runs summarized at this step
+	Loss       Summary     `json:"loss"`
+	Params     []Summary   `json:"params"`
+	Cloud      [][]float64 `json:"cloud"`      // the parameters of each summarized run
+	Covariance [][]float64 `json:"covariance"` // of the parameters
+	Ellipse    *Ellipse    `json:"ellipse,omitempty"`
+}
+
+// Result is an ensemble: every trajectory and the per-step aggregates
+type Result struct {
+	Vary       string       `json:"vary"`
+	ParamNames []string     `json:"param_names"`
+	Quantiles  []float64    `json:"quantiles"`
+	Runs       []Trajectory `json:"runs"`
+	Steps      []Step       `json:"steps"`
+}
+
+// Summarize aggregates the traces of the runs step by step, up to the longest
+// run; trace k is the run of seed config.SeedOf(k). Steps that no run reached
+// are left out.
+func Summarize(config Config, traces []insight.Trace) Result {
+	var paramNames []string
+	runs := make([]Trajectory, len(traces))
+	for k, t := range traces {
+		if paramNames == nil {
+			paramNames = t.ParamNames
+		}
+		runs[k] = Trajectory{Seed: config.SeedOf(k), Losses: t.Loss, Params: t.Params}
+	}
+	result := Result{
+		Vary:       config.Vary,
+		ParamNames: paramNames,
+		Quantiles:  config.quantiles(),
+		Runs:       runs,
+		Steps:      []Step{},
+	}
+	steps := 0
+	for _, run := range runs {
+		steps = max(steps, len(run.Losses))
+	}
+	for step := 0; step < steps; step++ {
+		var losses []float64
+		var cloud [][]float64
+		for _, run := range runs {
+			if step >= len(run.Losses) || !finite(run.Losses[step]) || !allFinite(run.Params[step]) {
+				continue
+			}
+			losses = append(losses, run.Losses[step])
+			cloud = append(cloud, run.Params[step])
+		}
+		if len(losses) == 0 {
+			continue
+		}
+		s := Step{
+			Step:   step,
+			Runs:   len(losses),
+			Loss:   summarize(losses, result.Quantiles),
+			Params: make([]Summary, len(paramNames)),
+			Cloud:  cloud,
+		}
+		for i := range paramNames {
+			values := make([]float64, len(cloud))
+			for k, p := range cloud {
+				values[k] = p[i]
+			}
+			s.Params[i] = summarize(values, result.Quantiles)
+		}
+		s.Covariance = covariance(cloud, s.Params)
+		if len(paramNames) == 2 {
+			e := ellipse(s.Covariance, [2]float64{s.Params[0].Mean, s.Params[1].Mean})
+			s.Ellipse = &e
+		}
+		result.Steps = append(result.Steps, s)
+	}
+	return result
+}
+
+// summarize returns the moments, range and quantiles of values
+func summarize(values []float64, quantiles []float64) Summary {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+	s := Summary{Min: sorted[0], Max: sorted[len(sorted)-1], Quantiles: make([]float64, len(quantiles))}
+	for _, v := range sorted {
+		s.Mean += v / n
+	}
+	for _, v := range sorted {
+		s.Std += (v - s.Mean) * (v - s.Mean) / n
+	}
+	s.Std = math.Sqrt(s.Std)
+	for i, q := range quantiles {
+		s.Quantiles[i] = quantile(sorted, q)
+	}
+	return s
+}
+
+// quantile interpolates linearly between the order statistics of sorted values
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// covariance returns the population covariance matrix of the cloud
+func covariance(cloud [][]float64, params []Summary) [][]float64 {
+	n := float64(len(cloud))
+	cov := make([][]float64, len(params))
+	for i := range cov {
+		cov[i] = make([]float64, len(params))
+		for j := range cov[i] {
+			for _, p := range cloud {
+				cov[i][j] += (p[i] - params[i].Mean) * (p[j] - params[j].Mean) / n
+			}
+		}
+	}
+	return cov
+}
+
+// ellipse diagonalizes a 2×2 covariance in closed form
+func ellipse(cov [][]float64, center [2]float64) Ellipse {
+	a, b, d := cov[0][0], cov[0][1], cov[1][1]
+	mid := (a + d) / 2
+	r := math.Hypot((a-d)/2, b)
+	angle := 0.5 * math.Atan2(2*b, a-d)
+	c, s := math.Cos(angle), math.Sin(angle)
+	return Ellipse{
+		Center: center,
+		Axes:   [2][2]float64{{c, s}, {-s, c}},
+		Radii:  [2]float64{math.Sqrt(math.Max(mid+r, 0)), math.Sqrt(math.Max(mid-r, 0))},
+		Angle:  angle,
+	}
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func allFinite(values []float64) bool {
+	for _, v := range values {
+		if !finite(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,257 @@
+// Package expect checks the quantitative claims of a case against its training
+// run. Case files declare expectations next to their insights; the generators
+// measure every run and fail when a case no longer shows what it claims.
+package expect
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ConvergeTolerance is the fraction of the total loss change still left when a
+// run counts as converged
+const ConvergeTolerance = 0.01
+
+// ParamTolerance is the distance from the final parameters, relative to the
+// distance from the initial ones, within which a run counts as converged. On a
+// quadratic loss, 1% of the loss change left is about 10% of the distance left.
+const ParamTolerance = 0.1
+
+// OscillationThreshold is the number of consecutive updates that must reverse
+// a parameter's direction for the run to count as oscillating
+const OscillationThreshold = 3
+
+// Range bounds a metric. Either end may be omitted.
+type Range struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// Expectations are the machine-checkable claims of a case
+type Expectations struct {
+	FinalLoss          *Range `json:"final_loss,omitempty"`
+	StepsToConverge    *Range `json:"steps_to_converge,omitempty"`
+	LossDrop           *Range `json:"loss_drop,omitempty"`
+	Oscillation        *bool  `json:"oscillation,omitempty"`
+	SaturationFraction *Range `json:"saturation_fraction,omitempty"` // Phase 3 only
+	DeadReLUFraction   *Range `json:"dead_relu_fraction,omitempty"`  // Phase 3 ReLU only
+}
+
+// Metrics are the measured properties of a training run
+type Metrics struct {
+	FinalLoss float64 `json:"final_loss"`
+
+	// StepsToConverge is the first step from which the loss stays within
+	// ConvergeTolerance of its total change and the parameters within
+	// ParamTolerance of their final values; -1 if the run is not finite
+	StepsToConverge int `json:"steps_to_converge"`
+
+	// LossDrop is the initial loss minus the final loss; 0 when nothing is learned
+	LossDrop float64 `json:"loss_drop"`
+
+	// Zigzag is the longest streak of consecutive updates reversing a parameter's direction
+	Zigzag int `json:"zigzag"`
+
+	// SaturationFraction is the fraction of points with |σ'(z)| < 0.01 at the last step
+	SaturationFraction float64 `json:"saturation_fraction"`
+
+	// DeadReLUFraction is the fraction of points with a zero ReLU gradient at the last step
+	DeadReLUFraction float64 `json:"dead_relu_fraction"`
+}
+
+// Oscillates reports whether the run oscillates
+func (m Metrics) Oscillates() bool {
+	return m.Zigzag >= OscillationThreshold
+}
+
+// Validate checks that every range is well-formed
+func (e *Expectations) Validate() error {
+	if e == nil {
+		return nil
+	}
+	for _, r := range []struct {
+		name  string
+		rng   *Range
+		unit  bool // a fraction in [0, 1]
+		count bool // a step count, >= 0
+	}{
+		{"final_loss", e.FinalLoss, false, false},
+		{"steps_to_converge", e.StepsToConverge, false, true},
+		{"loss_drop", e.LossDrop, false, false},
+		{"saturation_fraction", e.SaturationFraction, true, false},
+		{"dead_relu_fraction", e.DeadReLUFraction, true, false},
+	} {
+		if r.rng == nil {
+			continue
+		}
+		if r.rng.Min == nil && r.rng.Max == nil {
+			return fmt.Errorf("expect.%s: set min, max or both", r.name)
+		}
+		if r.rng.Min != nil && r.rng.Max != nil && *r.rng.Min > *r.rng.Max {
+			return fmt.Errorf("expect.%s: min %g is greater than max %g", r.name, *r.rng.Min, *r.rng.Max)
+		}
+		for _, v := range []*float64{r.rng.Min, r.rng.Max} {
+			if v == nil {
+				continue
+			}
+			if r.unit && (*v < 0 || *v > 1) {
+				return fmt.Errorf("expect.%s: %g is not a fraction between 0 and 1", r.name, *v)
+			}
+			if r.count && *v < 0 {
+				return fmt.Errorf("expect.%s: %g is not a step count", r.name, *v)
+			}
+		}
+	}
+	return nil
+}
+
+// HasNeuronClaims reports whether e bounds a metric only a neuron has
+func (e *Expectations) HasNeuronClaims() bool {
+	return e != nil && (e.SaturationFraction != nil || e.DeadReLUFraction != nil)
+}
+
+// Check returns an error listing every expectation the metrics violate
+func (e *Expectations) Check(m Metrics) error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	add(e.FinalLoss.check("final loss", m.FinalLoss))
+	if e.StepsToConverge != nil {
+		if m.StepsToConverge < 0 {
+			add(fmt.Errorf("steps to converge: the loss diverged, expected %s", e.StepsToConverge))
+		} else {
+			add(e.StepsToConverge.check("steps to converge", float64(m.StepsToConverge)))
+		}
+	}
+	add(e.LossDrop.check("loss drop", m.LossDrop))
+	if e.Oscillation != nil && *e.Oscillation != m.Oscillates() {
+		if *e.Oscillation {
+			add(fmt.Errorf("oscillation: expected some, but no parameter reverses direction more than %d times in a row", m.Zigzag))
+		} else {
+			add(fmt.Errorf("oscillation: expected none, but a parameter reverses direction %d times in a row", m.Zigzag))
+		}
+	}
+	add(e.SaturationFraction.check("saturation fraction", m.SaturationFraction))
+	add(e.DeadReLUFraction.check("dead ReLU fraction", m.DeadReLUFraction))
+	return errors.Join(errs...)
+}
+
+func (r *Range) check(name string, v float64) error {
+	if r == nil {
+		return nil
+	}
+	if math.IsNaN(v) || (r.Min != nil && v < *r.Min) || (r.Max != nil && v > *r.Max) {
+		return fmt.Errorf("%s: got %.6g, expected %s", name, v, r)
+	}
+	return nil
+}
+
+// String formats the range, e.g. "between 0 and 0.5" or "at most 10"
+func (r *Range) String() string {
+	switch {
+	case r.Min != nil && r.Max != nil:
+		return fmt.Sprintf("between %g and %g", *r.Min, *r.Max)
+	case r.Min != nil:
+		return fmt.Sprintf("at least %g", *r.Min)
+	case r.Max != nil:
+		return fmt.Sprintf("at most %g", *r.Max)
+	}
+	return "any value"
+}
+
+// Measure computes the metrics of a run from the loss and parameters of each
+// snapshot. SaturationFraction and DeadReLUFraction are left to the caller.
+func Measure(loss []float64, params [][]float64) Metrics {
+	m := Metrics{FinalLoss: math.NaN(), StepsToConverge: -1, LossDrop: math.NaN()}
+	if len(loss) > 0 {
+		m.FinalLoss = loss[len(loss)-1]
+		m.StepsToConverge = StepsToConverge(loss, params)
+		m.LossDrop = loss[0] - m.FinalLoss
+	}
+	m.Zigzag = Zigzag(params)
+	return m
+}
+
+// StepsToConverge returns the first step from which the loss stays within
+// ConvergeTolerance of its total change and the parameters stay within
+// ParamTolerance·‖final − initial‖ of the final parameters, or -1 if the run is
+// not finite. A run still moving at the end converges only in its last steps.
+func StepsToConverge(loss []float64, params [][]float64) int {
+	if len(loss) == 0 {
+		return -1
+	}
+	for i, l := range loss {
+		if math.IsNaN(l) || math.IsInf(l, 0) || (i < len(params) && !allFinite(params[i])) {
+			return -1
+		}
+	}
+	final := loss[len(loss)-1]
+	tolerance := ConvergeTolerance * math.Abs(loss[0]-final)
+	step := len(loss) - 1
+	for step > 0 && math.Abs(loss[step-1]-final) <= tolerance {
+		step--
+	}
+	if len(params) == 0 {
+		return step
+	}
+
+	last := params[len(params)-1]
+	paramTolerance := ParamTolerance * distance(params[0], last)
+	paramStep := len(params) - 1
+	for paramStep > 0 && distance(params[paramStep-1], last) <= paramTolerance {
+		paramStep--
+	}
+	return max(step, paramStep)
+}
+
+// distance returns the Euclidean distance between a and b
+func distance(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
+
+func allFinite(v []float64) bool {
+	for _, x := range v {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Zigzag returns the longest streak of consecutive updates that reverse the
+// direction of one parameter. Updates too small to matter are skipped.
+func Zigzag(params [][]float64) int {
+	longest := 0
+	if len(params) == 0 {
+		return 0
+	}
+	for p := range params[0] {
+		streak := 0
+		prev := 0.0
+		for step := 1; step < len(params); step++ {
+			delta := params[step][p] - params[step-1][p]
+			if math.Abs(delta) <= 1e-12*math.Max(1, math.Abs(params[step][p])) {
+				continue
+			}
+			if prev != 0 && (delta > 0) != (prev > 0) {
+				streak++
+				longest = max(longest, streak)
+			} else {
+				streak = 0
+			}
+			prev = delta
+		}
+	}
+	return longest
+}
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+	"github.com/iOliverNguyen/ml-viz/go/table"
+)
+
+// Export formats
+const (
+	FormatCSV      = "csv"
+	FormatColumnar = "columnar"
+)
+
+// Export tables
+const (
+	TableSteps  = "steps"  // one row per training step
+	TablePoints = "points" // one row per training step and data point
+)
+
+// ExportTables is a run flattened into tidy tables
+type ExportTables struct {
+	Steps  *table.Table
+	Points *table.Table
+}
+
+// Table returns the table with the given name ("steps" or "points")
+func (t ExportTables) Table(name string) (*table.Table, error) {
+	switch name {
+	case TableSteps:
+		return t.Steps, nil
+	case TablePoints:
+		return t.Points, nil
+	}
+	return nil, fmt.Errorf("unknown table %q (want %s or %s)", name, TableSteps, TablePoints)
+}
+
+// FlattenSnapshots flattens the snapshots of any phase ([]Snapshot,
+// []linear.LinearSnapshot or []neuron.NeuronSnapshot) into tidy tables
+func FlattenSnapshots(snapshots interface{}) (ExportTables, error) {
+	switch s := snapshots.(type) {
+	case []Snapshot:
+		return flattenPhase1(s)
+	case []linear.LinearSnapshot:
+		return flattenPhase2(s)
+	case []neuron.NeuronSnapshot:
+		return flattenPhase3(s)
+	}
+	return ExportTables{}, fmt.Errorf("cannot export snapshots of type %T", snapshots)
+}
+
+// DecodeSnapshots decodes the snapshots of a phase from JSON. Accepts a bare
+// snapshot array or any object with a "snapshots" field (runs, case files).
+func DecodeSnapshots(phase int, data []byte) (interface{}, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var wrapper struct {
+			Snapshots json.RawMessage `json:"snapshots"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, err
+		}
+		if wrapper.Snapshots == nil {
+			return nil, fmt.Errorf("no snapshots field found")
+		}
+		data = wrapper.Snapshots
+	}
+
+	switch phase {
+	case 1:
+		var snapshots []Snapshot
+		return snapshots, decodePhaseSnapshots(phase, data, &snapshots)
+	case 2:
+		var snapshots []linear.LinearSnapshot
+		return snapshots, decodePhaseSnapshots(phase, data, &snapshots)
+	case 3:
+		var snapshots []neuron.NeuronSnapshot
+		return snapshots, decodePhaseSnapshots(phase, data, &snapshots)
+	}
+	return nil, fmt.Errorf("unknown phase %d", phase)
+}
+
+func decodePhaseSnapshots(phase int, data []byte, out interface{}) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode phase %d snapshots: %w", phase, err)
+	}
+	return nil
+}
+
+// PhaseNumber converts a run phase ("phase1", ...) to its number
+func PhaseNumber(phase string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(phase, "phase"))
+	if err != nil || n < 1 || n > 3 {
+		return 0, fmt.Errorf("unknown phase %q", phase)
+	}
+	return n, nil
+}
+
+// WriteTable writes a table in the given format
+func WriteTable(w io.Writer, t *table.Table, format string) error {
+	switch format {
+	case FormatCSV:
+		return t.WriteCSV(w)
+	case FormatColumnar:
+		return t.WriteColumnar(w)
+	}
+	return fmt.Errorf("unknown format %q (want %s or %s)", format, FormatCSV, FormatColumnar)
+}
+
+// ExportFileExt returns the file extension used for a format
+func ExportFileExt(format string) string {
+	if format == FormatColumnar {
+		return ".mlvc"
+	}
+	return ".csv"
+}
+
+// ExportSnapshots writes steps and points tables of snapshots to outputDir
+func ExportSnapshots(snapshots interface{}, outputDir, format string) error {
+	tables, err := FlattenSnapshots(snapshots)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	for _, t := range []*table.Table{tables.Steps, tables.Points} {
+		path := filepath.Join(outputDir, t.Name+ExportFileExt(format))
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := WriteTable(f, t, format); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("  ✓ Wrote %s (%d rows)\n", path, t.NumRows())
+	}
+	return nil
+}
+
+func flattenPhase1(snapshots []Snapshot) (ExportTables, error) {
+	steps := table.New(TableSteps,
+		table.Int("step"), table.Float("w"), table.Float("grad_w"), table.Float("loss"),
+		table.Float("lr"), table.Float("delta_w"), table.Float("w_new"))
+	points := table.New(TablePoints,
+		table.Int("step"), table.Int("point"), table.Float("x"), table.Float("y_true"),
+		table.Float("y_pred"), table.Float("point_loss"), table.Float("point_grad"))
+
+	for _, s := range snapshots {
+		u := s.UpdateComponents
+		if err := steps.Append(s.Step, s.W, s.GradW, s.Loss, u.LR, u.DeltaW, u.WNew); err != nil {
+			return ExportTables{}, err
+		}
+		for i, p := range s.PointDetails {
+			if err := points.Append(s.Step, i, p.X, p.YTrue, p.YPred, p.PointLoss, p.PointGrad); err != nil {
+				return ExportTables{}, err
+			}
+		}
+	}
+	return ExportTables{Steps: steps, Points: points}, nil
+}
+
+func flattenPhase2(snapshots []linear.LinearSnapshot) (ExportTables, error) {
+	steps := table.New(TableSteps,
+		table.Int("step"), table.Float("w1"), table.Float("w2"),
+		table.Float("grad_w1"), table.Float("grad_w2"), table.Float("loss"),
+		table.Float("gradient_magnitude"), table.Float("gradient_direction"), table.Float("lr"),
+		table.Float("delta_w1"), table.Float("delta_w2"), table.Float("w1_new"), table.Float("w2_new"),
+		table.Float("gradient_turn_angle"), table.Float("step_optimum_angle"), table.Float("path_efficiency"),
+		table.Int("sign_flips_w1"), table.Int("sign_flips_w2"), table.Float("error_eigen1"), table.Float("error_eigen2"),
+		table.Float("search_direction_w1"), table.Float("search_direction_w2"), table.Float("step_length"),
+		table.Float("conjugacy_beta"), table.Int("coordinate"))
+	points := table.New(TablePoints,
+		table.Int("step"), table.Int("point"), table.Float("x1"), table.Float("x2"),
+		table.Float("y_true"), table.Float("y_pred"), table.Float("point_loss"),
+		table.Float("grad_w1"), table.Float("grad_w2"))
+
+	for _, s := range snapshots {
+		u := s.UpdateComponents
+		err := steps.Append(s.Step, s.W1, s.W2, s.GradW1, s.GradW2, s.Loss,
+			s.GradientMagnitude, s.GradientDirection, u.LR, u.DeltaW1, u.DeltaW2, u.W1New, u.W2New,
+			s.GradientTurnAngle, s.StepOptimumAngle, s.PathEfficiency, s.SignFlipsW1, s.SignFlipsW2, s.ErrorEigen1, s.ErrorEigen2,
+			s.SearchDirection[0], s.SearchDirection[1], s.StepLength, s.ConjugacyBeta, s.Coordinate)
+		if err != nil {
+			return ExportTables{}, err
+		}
+		for i, p := range s.PointDetails {
+			if err := points.Append(s.Step, i, p.X1, p.X2, p.YTrue, p.YPred, p.PointLoss, p.GradW1, p.GradW2); err != nil {
+				return ExportTables{}, err
+			}
+		}
+	}
+	return ExportTables{Steps: steps, Points: points}, nil
+}
+
+func flattenPhase3(snapshots []neuron.NeuronSnapshot) (ExportTables, error) {
+	// The number of weights is fixed for a run; take it from the first step
+	numWeights := 0
+	if len(snapshots) > 0 {
+		numWeights = len(snapshots[0].Params.W)
+	}
+	indexed := func(prefix string) []*table.Column {
+		cols := make([]*table.Column, numWeights)
+		for i := range cols {
+			cols[i] = table.Float(fmt.Sprintf("%s%d", prefix, i+1))
+		}
+		return cols
+	}
+
+	stepCols := []*table.Column{table.Int("step"), table.Str("activation")}
+	stepCols = append(stepCols, indexed("w")...)
+	stepCols = append(stepCols, table.Float("b"))
+	stepCols = append(stepCols, indexed("grad_w")...)
+	stepCols = append(stepCols, table.Float("grad_b"), table.Float("z"), table.Float("a"),
+		table.Float("dL_dz"), table.Float("dL_da"), table.Float("local_derivative"),
+		table.BoolCol("in_saturation_zone"), table.Float("loss"), table.Float("learning_rate"),
+		table.Float("gradient_magnitude"))
+	stepCols = append(stepCols, indexed("update_w")...)
+	stepCols = append(stepCols, table.Float("update_b"), table.Float("step_size"))
+	steps := table.New(TableSteps, stepCols...)
+
+	pointCols := []*table.Column{table.Int("step"), table.Int("point")}
+	pointCols = append(pointCols, indexed("x")...)
+	pointCols = append(pointCols, table.Float("y_true"), table.Float("z"), table.Float("a"),
+		table.Float("loss"), table.Float("dL_da"), table.Float("da_dz"), table.Float("dL_dz"))
+	pointCols = append(pointCols, indexed("dL_dw")...)
+	pointCols = append(pointCols, table.Float("dL_db"), table.BoolCol("in_saturation"))
+	points := table.New(TablePoints, pointCols...)
+
+	for _, s := range snapshots {
+		if len(s.Params.W) != numWeights || len(s.Grads.GradW) != numWeights || len(s.UpdateComponents.UpdateW) != numWeights {
+			return ExportTables{}, fmt.Errorf("step %d: expected %d weights", s.Step, numWeights)
+		}
+
+		u := s.UpdateComponents
+		row := []interface{}{s.Step, s.Activation}
+		row = appendFloats(row, s.Params.W)
+		row = append(row, s.Params.B)
+		row = appendFloats(row, s.Grads.GradW)
+		row = append(row, s.Grads.GradB, s.Z, s.A, s.DLdz, s.DLda, s.LocalDerivative,
+			s.InSaturationZone, s.Loss, u.LearningRate, u.GradMagnitude)
+		row = appendFloats(row, u.UpdateW)
+		row = append(row, u.UpdateB, u.StepSize)
+		if err := steps.Append(row...); err != nil {
+			return ExportTables{}, err
+		}
+
+		for _, p := range s.PointDetails {
+			if len(p.X) != numWeights || len(p.DLdw) != numWeights {
+				return ExportTables{}, fmt.Errorf("step %d, point %d: expected %d features", s.Step, p.Index, numWeights)
+			}
+			row := []interface{}{s.Step, p.Index}
+			row = appendFloats(row, p.X)
+			row = append(row, p.YTrue, p.Z, p.A, p.Loss, p.DLda, p.DaDz, p.DLdz)
+			row = appendFloats(row, p.DLdw)
+			row = append(row, p.DLdb, p.InSaturation)
+			if err := points.Append(row...); err != nil {
+				return ExportTables{}, err
+			}
+		}
+	}
+	return ExportTables{Steps: steps, Points: points}, nil
+}
+
+func appendFloats(row []interface{}, values []float64) []interface{} {
+	for _, v := range values {
+		row = append(row, v)
+	}
+	return row
+}
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/expect"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
 )
 
-// CaseConfig defines a training scenario for the case library
+// CaseConfig defines a training scenario for the case library, declared in a file under cases/
 type CaseConfig struct {
 	ID          string         `json:"id"`
 	Name        string         `json:"name"`
@@ -17,6 +21,12 @@ type CaseConfig struct {
 	DataConfig  DataGenConfig  `json:"data_config"`
 	Training    TrainingConfig `json:"training_config"`
 	Insights    []string       `json:"insights"`
+
+	// Expect holds the checkable claims of the insights; generation fails when they no longer hold
+	Expect *expect.Expectations `json:"expect,omitempty"`
+
+	// Race trains other configs on the case's dataset and ranks them
+	Race *Race `json:"race,omitempty"`
 }
 
 // CaseManifest contains metadata for all cases
@@ -25,258 +35,105 @@ type CaseManifest struct {
 	Cases   []CaseConfig `json:"cases"`
 }
 
-// GenerateCases generates 8 pre-computed training cases for the case library
-func GenerateCases() error {
-	// Define the 8 beginner cases
-	cases := []CaseConfig{
-		// Foundational cases
-		{
-			ID:          "perfect-start",
-			Name:        "Perfect Start",
-			Description: "Clean data, good learning rate, ideal initialization. Everything goes smoothly!",
-			Emoji:       "✓",
-			Category:    "foundational",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.1,
-				Seed:       42,
-			},
-			Training: TrainingConfig{
-				WInit: 0.0,
-				LR:    0.001,
-				Steps: 100,
-			},
-			Insights: []string{
-				"Loss decreases smoothly every step",
-				"Line quickly moves toward the data points",
-				"Final w ≈ 2.0 matches the true slope",
-			},
-		},
-		{
-			ID:          "noisy-but-ok",
-			Name:        "Noisy But OK",
-			Description: "Moderate noise in the data. Training still works, but loss won't reach zero.",
-			Emoji:       "✓",
-			Category:    "foundational",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.5,
-				Seed:       123,
-			},
-			Training: TrainingConfig{
-				WInit: 0.0,
-				LR:    0.001,
-				Steps: 100,
-			},
-			Insights: []string{
-				"Loss decreases but stabilizes above zero",
-				"Noise prevents perfect fit",
-				"This is normal in real-world data",
-			},
-		},
-		{
-			ID:          "very-noisy",
-			Name:        "Very Noisy",
-			Description: "High noise makes training harder. Loss decreases slowly and stays high.",
-			Emoji:       "⚠",
-			Category:    "foundational",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 2.0,
-				Seed:       456,
-			},
-			Training: TrainingConfig{
-				WInit: 0.0,
-				LR:    0.001,
-				Steps: 100,
-			},
-			Insights: []string{
-				"Loss decreases but remains high",
-				"Line struggles to find pattern in noisy data",
-				"May need more data or different model",
-			},
-		},
+// GenerateCaseSnapshots generates the case's dataset and trains on it
+func GenerateCaseSnapshots(caseConfig CaseConfig) ([]Snapshot, error) {
+	// Generate data
+	data, err := GenerateRandomData(caseConfig.DataConfig);
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data for case %s: %w", caseConfig.ID, err);
+	}
 
-		// Learning rate cases
-		{
-			ID:          "lr-too-slow",
-			Name:        "Learning Too Slow",
-			Description: "Learning rate is too small. Training barely moves!",
-			Emoji:       "😴",
-			Category:    "learning-rate",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.1,
-				Seed:       42,
-			},
-			Training: TrainingConfig{
-				WInit: 0.0,
-				LR:    0.00001,
-				Steps: 200,
-			},
-			Insights: []string{
-				"Barely moving after 200 steps",
-				"Loss decreases extremely slowly",
-				"Would need thousands of steps to converge",
-			},
-		},
-		{
-			ID:          "lr-just-right",
-			Name:        "Learning Just Right",
-			Description: "Perfect learning rate. Fast convergence without overshooting.",
-			Emoji:       "✓",
-			Category:    "learning-rate",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.1,
-				Seed:       42,
-			},
-			Training: TrainingConfig{
-				WInit: 0.5,
-				LR:    0.002,
-				Steps: 100,
-			},
-			Insights: []string{
-				"Converges in ~50 steps",
-				"Smooth, steady improvement",
-				"This is what good training looks like",
-			},
-		},
-		{
-			ID:          "lr-too-fast",
-			Name:        "Learning Too Fast",
-			Description: "Learning rate is too large. Watch the line bounce around!",
-			Emoji:       "⚠",
-			Category:    "learning-rate",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.1,
-				Seed:       42,
-			},
-			Training: TrainingConfig{
-				WInit: 0.8,
-				LR:    0.008,
-				Steps: 100,
-			},
-			Insights: []string{
-				"Converges quickly but overshoots",
-				"Shows bouncing behavior in first steps",
-				"Eventually stabilizes but less smoothly",
-			},
-		},
+	// Run training
+	return RunTrainingWithDataset(data, caseConfig.Training), nil;
+}
 
-		// Initialization cases
-		{
-			ID:          "start-at-zero",
-			Name:        "Start at Zero",
-			Description: "Initialize w=0. Line starts completely flat.",
-			Emoji:       "✓",
-			Category:    "initialization",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.1,
-				Seed:       42,
-			},
-			Training: TrainingConfig{
-				WInit: 0.0,
-				LR:    0.001,
-				Steps: 100,
-			},
-			Insights: []string{
-				"Starts with flat line (w=0)",
-				"Gradually tilts upward toward data",
-				"Common initialization choice",
-			},
-		},
-		{
-			ID:          "start-far-away",
-			Name:        "Start Far Away",
-			Description: "Initialize w=-3. Line starts pointing the wrong direction!",
-			Emoji:       "⚠",
-			Category:    "initialization",
-			DataConfig: DataGenConfig{
-				NumPoints:  10,
-				XMin:       1.0,
-				XMax:       10.0,
-				TrueSlope:  2.0,
-				NoiseLevel: 0.1,
-				Seed:       42,
-			},
-			Training: TrainingConfig{
-				WInit: -3.0,
-				LR:    0.001,
-				Steps: 150,
-			},
-			Insights: []string{
-				"Starts with negative slope",
-				"Takes longer to reach target",
-				"Bad initialization wastes training time",
-			},
-		},
-	};
+// MeasureSnapshots measures a Phase 1 run for checking case expectations
+func MeasureSnapshots(snapshots []Snapshot) expect.Metrics {
+	t := TraceSnapshots(snapshots);
+	return expect.Measure(t.Loss, t.Params);
+}
+
+// CheckCase checks a case's expectations against its snapshots
+func CheckCase(caseConfig CaseConfig, snapshots []Snapshot) error {
+	if err := caseConfig.Expect.Check(MeasureSnapshots(snapshots)); err != nil {
+		return fmt.Errorf("case %s no longer demonstrates its insights:\n%w", caseConfig.ID, err);
+	}
+	return nil;
+}
+
+// CaseKey returns the result cache key of a case's snapshots. Only the data and
+// training configs affect the snapshots, so editing a case's text keeps its key.
+func CaseKey(caseConfig CaseConfig) (string, error) {
+	return cache.Key(cache.KeyInput{
+		Phase:          "phase1-case",
+		DataConfig:     caseConfig.DataConfig,
+		TrainingConfig: caseConfig.Training,
+	});
+}
+
+// GenerateCases generates the pre-computed training cases of the case library
+func GenerateCases() error {
+	return GenerateCasesCached("js/public/cases", nil);
+}
+
+// GenerateCasesCached generates the case library into outputDir, reusing results
+// from store. Cases whose result is cached and whose snapshots file exists are skipped.
+func GenerateCasesCached(outputDir string, store *cache.Store) error {
+	cases := Cases();
 
 	// Create output directory
-	outputDir := "js/public/cases";
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err);
 	}
 
 	// Generate snapshots for each case
-	for _, caseConfig := range cases {
+	for i, caseConfig := range cases {
 		fmt.Printf("Generating case: %s (%s)\n", caseConfig.Name, caseConfig.ID);
 
-		// Generate data
-		data, err := GenerateRandomData(caseConfig.DataConfig);
+		caseDir := filepath.Join(outputDir, caseConfig.ID);
+		snapshotsPath := filepath.Join(caseDir, "snapshots.json");
+
+		// Generate data and run training, unless an identical result is cached
+		key, err := CaseKey(caseConfig);
 		if err != nil {
-			return fmt.Errorf("failed to generate data for case %s: %w", caseConfig.ID, err);
+			return fmt.Errorf("failed to hash case %s: %w", caseConfig.ID, err);
+		}
+		data, hit, err := store.GetOrCompute(key, func() (interface{}, error) {
+			return GenerateCaseSnapshots(caseConfig);
+		});
+		if err != nil {
+			return err;
+		}
+		var snapshots []Snapshot;
+		if err := json.Unmarshal(data, &snapshots); err != nil {
+			return fmt.Errorf("failed to decode snapshots of case %s: %w", caseConfig.ID, err);
+		}
+		if err := CheckCase(caseConfig, snapshots); err != nil {
+			return err;
+		}
+		if err := generateRace(caseDir, caseConfig, store); err != nil {
+			return err;
+		}
+		// Cases without written insights get the observed ones
+		if len(caseConfig.Insights) == 0 {
+			cases[i].Insights = insight.Messages(insight.Analyze(TraceSnapshots(snapshots)));
+		}
+		if _, statErr := os.Stat(snapshotsPath); hit && statErr == nil {
+			fmt.Printf("  ✓ Unchanged, skipped %s\n", snapshotsPath);
+			continue;
 		}
-
-		// Run training
-		snapshots := RunTrainingWithDataset(data, caseConfig.Training);
 
 		// Create case directory
-		caseDir := filepath.Join(outputDir, caseConfig.ID);
 		if err := os.MkdirAll(caseDir, 0755); err != nil {
 			return fmt.Errorf("failed to create case directory: %w", err);
 		}
 
 		// Save snapshots
-		snapshotsPath := filepath.Join(caseDir, "snapshots.json");
-		snapshotsFile, err := os.Create(snapshotsPath);
-		if err != nil {
-			return fmt.Errorf("failed to create snapshots file: %w", err);
-		}
-
-		encoder := json.NewEncoder(snapshotsFile);
-		encoder.SetIndent("", "  ");
-		if err := encoder.Encode(snapshots); err != nil {
-			snapshotsFile.Close();
-			return fmt.Errorf("failed to encode snapshots: %w", err);
+		if err := cache.WriteIndented(snapshotsPath, data); err != nil {
+			return fmt.Errorf("failed to write snapshots: %w", err);
 		}
-		snapshotsFile.Close();
 
-		fmt.Printf("  ✓ Saved %d snapshots to %s\n", len(snapshots), snapshotsPath);
+		fmt.Printf("  ✓ Saved snapshots to %s\n", snapshotsPath);
 	}
 
 	// Create manifest
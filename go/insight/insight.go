@@ -0,0 +1,282 @@
+// Package insight derives observations from a training run: where it
+// converges, overshoots, oscillates, stalls, saturates or diverges. Every
+// observation references the steps it describes, so the UI can jump to them.
+package insight
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/iOliverNguyen/ml-viz/go/expect"
+)
+
+// Kind is the type of an observation
+type Kind string
+
+const (
+	KindConvergence     Kind = "convergence"
+	KindStillImproving  Kind = "still-improving"
+	KindOvershoot       Kind = "overshoot"
+	KindOscillation     Kind = "oscillation"
+	KindPlateau         Kind = "plateau"
+	KindSaturationOnset Kind = "saturation-onset"
+	KindDivergence      Kind = "divergence"
+)
+
+const (
+	// DivergenceFactor is how far the loss must grow above its initial value to
+	// count as diverging, in insights, race standings and fitted loss grids
+	DivergenceFactor = 10
+
+	// MinImprovement is the fraction of the initial loss a run must remove to count as learning
+	MinImprovement = 0.01
+
+	// PlateauMinSteps is the shortest stretch of flat loss reported as a plateau
+	PlateauMinSteps = 10
+
+	// PlateauTolerance is the largest per-step loss change, relative to the initial loss, on a plateau
+	PlateauTolerance = 1e-4
+
+	// MinMove is the smallest update, relative to a parameter's range over the
+	// run, that counts when looking for overshoots and oscillations
+	MinMove = 0.01
+
+	// SaturationOnsetFraction is the fraction of saturated points that marks saturation onset
+	SaturationOnsetFraction = 0.1
+)
+
+// Trace is the phase-independent view of a run that the analyzer works on
+type Trace struct {
+	Steps      []int       // step number of each snapshot
+	Loss       []float64   // loss of each snapshot
+	ParamNames []string    // e.g. w1, w2, b
+	Params     [][]float64 // parameters of each snapshot, in ParamNames order
+
+	// Phase 3 only: the activation and, per snapshot, the fraction of points
+	// with |σ'(z)| < 0.01 (for ReLU: a zero gradient, i.e. dead)
+	Activation string
+	Saturated  []float64
+}
+
+// Observation is one derived insight. Events have EndStep == Step.
+type Observation struct {
+	Kind    Kind    `json:"kind"`
+	Step    int     `json:"step"`
+	EndStep int     `json:"end_step"`
+	Param   string  `json:"param,omitempty"`
+	Value   float64 `json:"value"` // loss, parameter value, fraction or count; see Message
+	Message string  `json:"message"`
+}
+
+// Analyze returns the observations of a run, ordered by step
+func Analyze(t Trace) []Observation {
+	obs := []Observation{}
+	if len(t.Loss) == 0 {
+		return obs
+	}
+
+	diverged := divergence(t)
+	if diverged != nil {
+		obs = append(obs, *diverged)
+	} else {
+		obs = append(obs, convergence(t)...)
+	}
+	obs = append(obs, reversals(t)...)
+	if diverged == nil {
+		obs = append(obs, plateaus(t)...)
+	}
+	if onset := saturationOnset(t); onset != nil {
+		obs = append(obs, *onset)
+	}
+
+	sort.SliceStable(obs, func(i, j int) bool { return obs[i].Step < obs[j].Step })
+	return obs
+}
+
+// Messages returns the messages of observations, e.g. to fill a case's insights
+func Messages(obs []Observation) []string {
+	messages := make([]string, len(obs))
+	for i, o := range obs {
+		messages[i] = o.Message
+	}
+	return messages
+}
+
+func (t Trace) step(i int) int {
+	if i < len(t.Steps) {
+		return t.Steps[i]
+	}
+	return i
+}
+
+// divergence finds the first snapshot whose loss is not finite or has grown
+// DivergenceFactor times above the initial loss
+func divergence(t Trace) *Observation {
+	limit := DivergenceFactor * math.Max(t.Loss[0], 1e-12)
+	for i, l := range t.Loss {
+		if math.IsNaN(l) || math.IsInf(l, 0) || l > limit {
+			return &Observation{
+				Kind:    KindDivergence,
+				Step:    t.step(i),
+				EndStep: t.step(len(t.Loss) - 1),
+				Value:   l,
+				Message: fmt.Sprintf("Diverges: loss grows from %.4g to %.4g by step %d", t.Loss[0], l, t.step(i)),
+			}
+		}
+	}
+	return nil
+}
+
+// convergence reports where the loss settles, or that it is still dropping at the end
+func convergence(t Trace) []Observation {
+	first, final := t.Loss[0], t.Loss[len(t.Loss)-1]
+	if first-final < MinImprovement*math.Abs(first) {
+		return nil
+	}
+	last := len(t.Loss) - 1
+	i := expect.StepsToConverge(t.Loss, t.Params)
+	if i < 0 {
+		return nil
+	}
+	// A run still dropping over its last tenth has not settled
+	if i >= last-last/10 {
+		return []Observation{{
+			Kind:    KindStillImproving,
+			Step:    t.step(last),
+			EndStep: t.step(last),
+			Value:   final,
+			Message: fmt.Sprintf("Still improving at step %d: loss fell from %.4g to %.4g and keeps falling", t.step(last), first, final),
+		}}
+	}
+	return []Observation{{
+		Kind:    KindConvergence,
+		Step:    t.step(i),
+		EndStep: t.step(i),
+		Value:   t.Loss[i],
+		Message: fmt.Sprintf("Converges by step %d: loss settles near %.4g (from %.4g)", t.step(i), final, first),
+	}}
+}
+
+// reversals reports parameters turning back: isolated turns are overshoots,
+// runs of OscillationThreshold or more consecutive turns are oscillations.
+// Updates below MinMove of the parameter's range are ignored, so the wiggles
+// of a converged run are not reported.
+func reversals(t Trace) []Observation {
+	var obs []Observation
+	for p, name := range t.ParamNames {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, params := range t.Params {
+			lo, hi = math.Min(lo, params[p]), math.Max(hi, params[p])
+		}
+		minMove := MinMove * (hi - lo)
+		if !(minMove > 0) || math.IsInf(minMove, 0) {
+			continue
+		}
+
+		var turns []int // snapshot index of each turning point
+		flush := func() {
+			if len(turns) >= expect.OscillationThreshold {
+				start, end := turns[0], turns[len(turns)-1]
+				obs = append(obs, Observation{
+					Kind:    KindOscillation,
+					Step:    t.step(start),
+					EndStep: t.step(end),
+					Param:   name,
+					Value:   float64(len(turns)),
+					Message: fmt.Sprintf("%s zigzags from step %d to %d, reversing direction %d times in a row", name, t.step(start), t.step(end), len(turns)),
+				})
+			} else {
+				for _, i := range turns {
+					obs = append(obs, Observation{
+						Kind:    KindOvershoot,
+						Step:    t.step(i),
+						EndStep: t.step(i),
+						Param:   name,
+						Value:   t.Params[i][p],
+						Message: fmt.Sprintf("%s overshoots and turns back at step %d (%s = %.4g)", name, t.step(i), name, t.Params[i][p]),
+					})
+				}
+			}
+			turns = nil
+		}
+
+		prev := 0.0
+		for i := 1; i < len(t.Params); i++ {
+			delta := t.Params[i][p] - t.Params[i-1][p]
+			if math.Abs(delta) < minMove {
+				continue
+			}
+			if prev != 0 && (delta > 0) != (prev > 0) {
+				turns = append(turns, i-1)
+			} else if len(turns) > 0 {
+				flush()
+			}
+			prev = delta
+		}
+		flush()
+	}
+	return obs
+}
+
+// plateaus reports stretches of at least PlateauMinSteps snapshots where the
+// loss barely changes, before the run has converged
+func plateaus(t Trace) []Observation {
+	end := len(t.Loss) - 1
+	if first, final := t.Loss[0], t.Loss[end]; first-final >= MinImprovement*math.Abs(first) {
+		// Flat loss after convergence is expected, so only look before it
+		if i := expect.StepsToConverge(t.Loss, t.Params); i >= 0 {
+			end = i
+		}
+	}
+
+	tolerance := PlateauTolerance * math.Max(math.Abs(t.Loss[0]), 1e-12)
+	var obs []Observation
+	start := 0
+	for i := 1; i <= end+1; i++ {
+		if i <= end && math.Abs(t.Loss[i]-t.Loss[i-1]) <= tolerance {
+			continue
+		}
+		if i-start >= PlateauMinSteps {
+			obs = append(obs, Observation{
+				Kind:    KindPlateau,
+				Step:    t.step(start),
+				EndStep: t.step(i - 1),
+				Value:   t.Loss[i-1],
+				Message: fmt.Sprintf("Plateau from step %d to %d: loss stays at %.4g", t.step(start), t.step(i-1), t.Loss[i-1]),
+			})
+		}
+		start = i
+	}
+	return obs
+}
+
+// saturationOnset finds the first snapshot with at least SaturationOnsetFraction saturated points
+func saturationOnset(t Trace) *Observation {
+	for i, fraction := range t.Saturated {
+		if fraction < SaturationOnsetFraction {
+			continue
+		}
+		peak := fraction
+		for _, f := range t.Saturated[i:] {
+			peak = math.Max(peak, f)
+		}
+		what := "in the saturated zone (|σ'(z)| < 0.01)"
+		switch t.Activation {
+		case "relu":
+			what = "dead (ReLU gradient 0)"
+		case "hard_sigmoid":
+			what = "clipped (hard-sigmoid gradient 0)"
+		case "gelu", "swish":
+			what = "in the negative tail (|σ'(z)| < 0.01)"
+		}
+		return &Observation{
+			Kind:    KindSaturationOnset,
+			Step:    t.step(i),
+			EndStep: t.step(i),
+			Value:   fraction,
+			Message: fmt.Sprintf("From step %d, %.0f%% of points are %s, peaking at %.0f%%", t.step(i), fraction*100, what, peak*100),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+)
+
+// TraceSnapshots converts Phase 1 snapshots for the insight analyzer
+func TraceSnapshots(snapshots []Snapshot) insight.Trace {
+	t := insight.Trace{
+		Steps:      make([]int, len(snapshots)),
+		Loss:       make([]float64, len(snapshots)),
+		ParamNames: []string{"w"},
+		Params:     make([][]float64, len(snapshots)),
+	}
+	for i, snap := range snapshots {
+		t.Steps[i] = snap.Step
+		t.Loss[i] = snap.Loss
+		t.Params[i] = []float64{snap.W}
+	}
+	return t
+}
+
+// TraceAnySnapshots converts the snapshots of any phase ([]Snapshot,
+// []linear.LinearSnapshot or []neuron.NeuronSnapshot)
+func TraceAnySnapshots(snapshots interface{}) (insight.Trace, error) {
+	switch s := snapshots.(type) {
+	case []Snapshot:
+		return TraceSnapshots(s), nil
+	case []linear.LinearSnapshot:
+		return linear.TraceSnapshots2D(s), nil
+	case []neuron.NeuronSnapshot:
+		return neuron.TraceSnapshots(s), nil
+	}
+	return insight.Trace{}, fmt.Errorf("cannot analyze snapshots of type %T", snapshots)
+}
+
+// AnalyzeSnapshots derives the observations of the snapshots of any phase
+func AnalyzeSnapshots(snapshots interface{}) ([]insight.Observation, error) {
+	trace, err := TraceAnySnapshots(snapshots)
+	if err != nil {
+		return nil, err
+	}
+	return insight.Analyze(trace), nil
+}
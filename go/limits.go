@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Limits caps the work a single API request may ask the server to do
+type Limits struct {
+	MaxPoints         int           `json:"max_points"`          // dataset size (generated or custom)
+	MaxSteps          int           `json:"max_steps"`           // training steps
+	MaxBodyBytes      int64         `json:"max_body_bytes"`      // request body size
+	MaxGridResolution int           `json:"max_grid_resolution"` // Phase 2 loss grid resolution per axis
+	MaxFeatures       int           `json:"max_features"`        // features of Phase 2 N-feature regression
+	RequestTimeout    time.Duration `json:"request_timeout"`     // deadline for one training request (0 = none)
+}
+
+// DefaultLimits returns limits that fit a classroom server
+func DefaultLimits() Limits {
+	return Limits{
+		MaxPoints:         10000,
+		MaxSteps:          10000,
+		MaxBodyBytes:      1 << 20, // 1 MiB
+		MaxGridResolution: 200,
+		MaxFeatures:       100,
+		RequestTimeout:    30 * time.Second,
+	}
+}
+
+// APIError is the JSON body of every error response
+type APIError struct {
+	Error string `json:"error"`
+	Limit string `json:"limit,omitempty"` // name of the violated limit, if any
+	Value int64  `json:"value,omitempty"` // requested value
+	Max   int64  `json:"max,omitempty"`   // allowed maximum
+}
+
+// LimitError reports a request that exceeds one of the server limits
+type LimitError struct {
+	Limit string
+	Value int64
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeded: got %d, max %d", e.Limit, e.Value, e.Max)
+}
+
+// checkLimit returns a LimitError when value exceeds max (max <= 0 disables the limit)
+func checkLimit(limit string, value, max int) error {
+	if max > 0 && value > max {
+		return &LimitError{Limit: limit, Value: int64(value), Max: int64(max)}
+	}
+	return nil
+}
+
+// CheckPoints checks a dataset size against MaxPoints
+func (l Limits) CheckPoints(n int) error {
+	return checkLimit("max_points", n, l.MaxPoints)
+}
+
+// CheckSteps checks a step count against MaxSteps
+func (l Limits) CheckSteps(n int) error {
+	return checkLimit("max_steps", n, l.MaxSteps)
+}
+
+// CheckGridResolution checks a loss grid resolution against MaxGridResolution
+func (l Limits) CheckGridResolution(n int) error {
+	return checkLimit("max_grid_resolution", n, l.MaxGridResolution)
+}
+
+// CheckFeatures checks a feature count against MaxFeatures
+func (l Limits) CheckFeatures(n int) error {
+	return checkLimit("max_features", n, l.MaxFeatures)
+}
+
+// CheckTraining checks a dataset size and step count against the limits
+func (l Limits) CheckTraining(points, steps int) error {
+	if points < 0 {
+		return fmt.Errorf("num_points must be non-negative, got %d", points)
+	}
+	if steps < 0 {
+		return fmt.Errorf("steps must be non-negative, got %d", steps)
+	}
+	if err := l.CheckPoints(points); err != nil {
+		return err
+	}
+	return l.CheckSteps(steps)
+}
+
+// writeError writes err as a JSON APIError with the given status.
+// Limit violations are reported with status 422 and the limit's name.
+func writeError(w http.ResponseWriter, status int, message string, err error) {
+	body := APIError{Error: message}
+	if err != nil {
+		body.Error = message + ": " + err.Error()
+	}
+
+	var limitErr *LimitError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &limitErr):
+		status = http.StatusUnprocessableEntity
+		body.Limit = limitErr.Limit
+		body.Value = limitErr.Value
+		body.Max = limitErr.Max
+	case errors.As(err, &maxBytesErr):
+		status = http.StatusRequestEntityTooLarge
+		body.Limit = "max_body_bytes"
+		body.Max = maxBytesErr.Limit
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusServiceUnavailable
+		body.Limit = "request_timeout"
+	}
+
+	writeJSONResponse(w, status, body)
+}
+
+// withLimits caps the request body size and attaches the request deadline
+func (s *Server) withLimits(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.Limits.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, s.config.Limits.MaxBodyBytes)
+		}
+		if s.config.Limits.RequestTimeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), s.config.Limits.RequestTimeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		next(w, r)
+	}
+}
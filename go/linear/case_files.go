@@ -0,0 +1,99 @@
+package linear
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/iOliverNguyen/ml-viz/go/casefile"
+)
+
+// builtinCaseFiles holds the Phase 2 case library, one file per case
+//
+//go:embed cases/*.yaml
+var builtinCaseFiles embed.FS
+
+var caseLibrary = casefile.MustLoadLibrary(builtinCaseFiles, "cases",
+	func(c CaseConfig2D) string { return c.ID },
+	func(c *CaseConfig2D) error { return ValidateCase2D(*c) })
+
+// Cases2D returns the Phase 2 case library: the built-in cases plus those added with AddCaseFiles2D
+func Cases2D() []CaseConfig2D {
+	return caseLibrary.Cases()
+}
+
+// LoadCases2D decodes and validates Phase 2 case files
+func LoadCases2D(files []casefile.File) ([]CaseConfig2D, error) {
+	return caseLibrary.Load(files)
+}
+
+// AddCaseFiles2D loads the case files of dir into the library. A case with the
+// ID of an existing case replaces it.
+func AddCaseFiles2D(dir string) error {
+	return caseLibrary.AddDir(dir)
+}
+
+// ValidateCase2D checks that a case is complete and its data config usable
+func ValidateCase2D(c CaseConfig2D) error {
+	if err := casefile.ValidateID(c.ID); err != nil {
+		return err
+	}
+	if c.Name == "" || c.Category == "" {
+		return fmt.Errorf("case %s: name and category are required", c.ID)
+	}
+	d := c.DataConfig
+	if d.NumPoints <= 0 {
+		return fmt.Errorf("case %s: data_config.num_points must be positive", c.ID)
+	}
+	if d.Correlated == nil && (d.X1Max <= d.X1Min || d.X2Max <= d.X2Min) {
+		return fmt.Errorf("case %s: data_config x ranges must have max greater than min", c.ID)
+	}
+	if err := d.Correlated.Validate(); err != nil {
+		return fmt.Errorf("case %s: data_config.correlated: %w", c.ID, err)
+	}
+	if d.NoiseLevel < 0 {
+		return fmt.Errorf("case %s: data_config.noise_level must be non-negative", c.ID)
+	}
+	if err := validateTrainConfig(c.TrainConfig); err != nil {
+		return fmt.Errorf("case %s: training_config: %w", c.ID, err)
+	}
+	names := map[string]bool{}
+	for i, overlay := range c.Overlays {
+		if overlay.Name == "" || names[overlay.Name] {
+			return fmt.Errorf("case %s: overlays[%d] needs a unique name", c.ID, i)
+		}
+		names[overlay.Name] = true
+		if err := validateTrainConfig(overlay.TrainConfig); err != nil {
+			return fmt.Errorf("case %s: overlay %s: %w", c.ID, overlay.Name, err)
+		}
+		if err := overlay.Expect.Validate(); err != nil {
+			return fmt.Errorf("case %s: overlay %s: %w", c.ID, overlay.Name, err)
+		}
+		if overlay.Expect.HasNeuronClaims() {
+			return fmt.Errorf("case %s: overlay %s: saturation_fraction and dead_relu_fraction only apply to Phase 3", c.ID, overlay.Name)
+		}
+	}
+	if err := c.Race.Validate(); err != nil {
+		return fmt.Errorf("case %s: race: %w", c.ID, err)
+	}
+	if err := c.Expect.Validate(); err != nil {
+		return fmt.Errorf("case %s: %w", c.ID, err)
+	}
+	if c.Expect.HasNeuronClaims() {
+		return fmt.Errorf("case %s: saturation_fraction and dead_relu_fraction only apply to Phase 3", c.ID)
+	}
+	return nil
+}
+
+func validateTrainConfig(config TrainingConfig2D) error {
+	if err := ValidateTrainingConfig2D(config); err != nil {
+		return err
+	}
+	if config.MaxSteps <= 0 {
+		return fmt.Errorf("max_steps must be positive")
+	}
+	// The browser trains the cases, and only on full batches
+	if config.BatchSize != 0 {
+		return fmt.Errorf("batch_size is not supported by cases")
+	}
+	return nil
+}
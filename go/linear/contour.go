@@ -0,0 +1,233 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Contour level spacings of ContourConfig.Spacing
+const (
+	SpacingLog    = "log"    // levels grow geometrically from the minimum loss (the default)
+	SpacingLinear = "linear" // evenly spaced levels
+)
+
+// DefaultNumLevels is the number of automatic contour levels
+const DefaultNumLevels = 12
+
+// MaxContourLevels bounds the levels of one request
+const MaxContourLevels = 100
+
+// ContourConfig chooses the loss levels of iso-contours. Explicit Levels win;
+// otherwise NumLevels levels are spaced between the grid's minimum and maximum loss.
+type ContourConfig struct {
+	Levels    []float64 `json:"levels,omitempty"`
+	NumLevels int       `json:"num_levels,omitempty"` // default 12
+	Spacing   string    `json:"spacing,omitempty"`    // log (default) or linear
+
+	// OmitGrid drops the grid points from the response, keeping only its bounds
+	// and the contours, which are much smaller
+	OmitGrid bool `json:"omit_grid,omitempty"`
+}
+
+// Contour is the iso-line of one loss level: polylines of (w1, w2) vertices.
+// A closed polyline repeats its first vertex at the end.
+type Contour struct {
+	Level float64        `json:"level"`
+	Label string         `json:"label"`
+	Lines [][][2]float64 `json:"lines"`
+}
+
+// ValidateContourConfig checks the levels and spacing of a contour config
+func ValidateContourConfig(config ContourConfig) error {
+	switch config.Spacing {
+	case "", SpacingLog, SpacingLinear:
+	default:
+		return fmt.Errorf("unknown contour spacing %q (want %s or %s)", config.Spacing, SpacingLog, SpacingLinear)
+	}
+	if config.NumLevels < 0 || config.NumLevels > MaxContourLevels || len(config.Levels) > MaxContourLevels {
+		return fmt.Errorf("contours need between 0 and %d levels", MaxContourLevels)
+	}
+	for _, level := range config.Levels {
+		if math.IsNaN(level) || math.IsInf(level, 0) {
+			return fmt.Errorf("contour levels must be finite")
+		}
+	}
+	return nil
+}
+
+// ContourLevels returns the levels a config chooses for a grid, ascending
+func ContourLevels(grid LossGrid, config ContourConfig) []float64 {
+	if len(config.Levels) > 0 {
+		levels := append([]float64(nil), config.Levels...)
+		sort.Float64s(levels)
+		return levels
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range grid.Points {
+		if !math.IsNaN(p.Loss) && !math.IsInf(p.Loss, 0) {
+			lo, hi = math.Min(lo, p.Loss), math.Max(hi, p.Loss)
+		}
+	}
+	if !(hi > lo) {
+		return nil
+	}
+	n := config.NumLevels
+	if n == 0 {
+		n = DefaultNumLevels
+	}
+	levels := make([]float64, n)
+	for k := range levels {
+		t := float64(k+1) / float64(n+1)
+		if config.Spacing == SpacingLinear {
+			levels[k] = lo + t*(hi-lo)
+			continue
+		}
+		// Geometric spacing of the loss above the minimum, so the levels crowd
+		// into the valley where the trajectory ends
+		floor := (hi - lo) * 1e-4
+		levels[k] = lo + floor*math.Pow((hi-lo)/floor, t)
+	}
+	return levels
+}
+
+// ExtractContours traces the iso-contours of a loss grid by marching squares
+func ExtractContours(grid LossGrid, config ContourConfig) []Contour {
+	levels := ContourLevels(grid, config)
+	contours := make([]Contour, 0, len(levels))
+	for _, level := range levels {
+		contours = append(contours, Contour{
+			Level: level,
+			Label: strconv.FormatFloat(level, 'g', 3, 64),
+			Lines: marchingSquares(grid, level),
+		})
+	}
+	return contours
+}
+
+// gridEdge identifies a grid edge by its lower-left vertex (i, j) along w1 and
+// w2, and whether it runs along w1 (horizontal) or w2
+type gridEdge struct {
+	i, j       int
+	horizontal bool
+}
+
+// marchingSquares returns the polylines where the grid's loss crosses level
+func marchingSquares(grid LossGrid, level float64) [][][2]float64 {
+	res := grid.Resolution
+	if res < 2 || len(grid.Points) != res*res {
+		return [][][2]float64{}
+	}
+	// Points are laid out w1-major: index i*res + j is (w1 index i, w2 index j)
+	loss := func(i, j int) float64 { return grid.Points[i*res+j].Loss }
+	vertex := func(e gridEdge) [2]float64 {
+		a := grid.Points[e.i*res+e.j]
+		b := grid.Points[e.i*res+e.j+1]
+		if e.horizontal {
+			b = grid.Points[(e.i+1)*res+e.j]
+		}
+		t := 0.5
+		if a.Loss != b.Loss {
+			t = (level - a.Loss) / (b.Loss - a.Loss)
+		}
+		return [2]float64{a.W1 + t*(b.W1-a.W1), a.W2 + t*(b.W2-a.W2)}
+	}
+
+	var segments [][2]gridEdge
+	for i := 0; i < res-1; i++ {
+		for j := 0; j < res-1; j++ {
+			// Corners counter-clockwise from the lower left, and the edges
+			// between them: bottom, right, top, left
+			corners := [4]float64{loss(i, j), loss(i+1, j), loss(i+1, j+1), loss(i, j+1)}
+			edges := [4]gridEdge{{i, j, true}, {i + 1, j, false}, {i, j + 1, true}, {i, j, false}}
+			index := 0
+			for k, v := range corners {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					index = -1
+					break
+				}
+				if v > level {
+					index |= 1 << k
+				}
+			}
+			if index <= 0 || index == 15 {
+				continue
+			}
+			// The contour crosses every edge whose corners are on different sides
+			var crossed []int
+			for k := 0; k < 4; k++ {
+				if (index>>k)&1 != (index>>((k+1)%4))&1 {
+					crossed = append(crossed, k)
+				}
+			}
+			if len(crossed) == 2 {
+				segments = append(segments, [2]gridEdge{edges[crossed[0]], edges[crossed[1]]})
+				continue
+			}
+			// Saddle: all four edges are crossed. The cell's mean decides
+			// whether the high corners connect through the middle; the
+			// segments cut off the other two corners.
+			mean := (corners[0] + corners[1] + corners[2] + corners[3]) / 4
+			highConnected := mean > level
+			if (index == 5) == highConnected {
+				// Cut off corners 1 and 3
+				segments = append(segments, [2]gridEdge{edges[0], edges[1]}, [2]gridEdge{edges[2], edges[3]})
+			} else {
+				// Cut off corners 0 and 2
+				segments = append(segments, [2]gridEdge{edges[3], edges[0]}, [2]gridEdge{edges[1], edges[2]})
+			}
+		}
+	}
+	return joinSegments(segments, vertex)
+}
+
+// joinSegments chains segments sharing an edge crossing into polylines
+func joinSegments(segments [][2]gridEdge, vertex func(gridEdge) [2]float64) [][][2]float64 {
+	byEdge := map[gridEdge][]int{}
+	for k, s := range segments {
+		byEdge[s[0]] = append(byEdge[s[0]], k)
+		byEdge[s[1]] = append(byEdge[s[1]], k)
+	}
+	used := make([]bool, len(segments))
+	// next returns an unused segment touching e and its other end
+	next := func(e gridEdge) (gridEdge, bool) {
+		for _, k := range byEdge[e] {
+			if used[k] {
+				continue
+			}
+			used[k] = true
+			if segments[k][0] == e {
+				return segments[k][1], true
+			}
+			return segments[k][0], true
+		}
+		return gridEdge{}, false
+	}
+
+	lines := [][][2]float64{}
+	for k, s := range segments {
+		if used[k] {
+			continue
+		}
+		used[k] = true
+		// Grow the chain forward from one end, then backward from the other
+		forward := []gridEdge{s[0], s[1]}
+		for e, ok := next(s[1]); ok; e, ok = next(e) {
+			forward = append(forward, e)
+		}
+		var backward []gridEdge
+		for e, ok := next(s[0]); ok; e, ok = next(e) {
+			backward = append(backward, e)
+		}
+		line := make([][2]float64, 0, len(backward)+len(forward))
+		for b := len(backward) - 1; b >= 0; b-- {
+			line = append(line, vertex(backward[b]))
+		}
+		for _, e := range forward {
+			line = append(line, vertex(e))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
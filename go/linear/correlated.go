@@ -0,0 +1,91 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// FeatureCorrelation draws (x1, x2) from a bivariate normal with the given
+// means, variances and correlation. Correlated features, or uncentered ones far
+// from the origin, tilt and stretch the loss valley along a diagonal.
+type FeatureCorrelation struct {
+	Correlation float64 `json:"correlation"` // ρ in (-1, 1)
+	MeanX1      float64 `json:"mean_x1"`
+	MeanX2      float64 `json:"mean_x2"`
+	VarX1       float64 `json:"var_x1,omitempty"` // default 1
+	VarX2       float64 `json:"var_x2,omitempty"` // default 1
+}
+
+// Validate checks the correlation and variances. A nil config draws uniform features.
+func (c *FeatureCorrelation) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if !(c.Correlation > -1 && c.Correlation < 1) {
+		return fmt.Errorf("correlation must be in (-1, 1), got %g", c.Correlation)
+	}
+	if c.VarX1 < 0 || c.VarX2 < 0 {
+		return fmt.Errorf("feature variances must be positive")
+	}
+	if !isFinite(c.MeanX1) || !isFinite(c.MeanX2) || !isFinite(c.VarX1) || !isFinite(c.VarX2) {
+		return fmt.Errorf("feature means and variances must be finite")
+	}
+	return nil
+}
+
+// sample draws one (x1, x2): x1 = μ1 + σ1 z1 and x2 = μ2 + σ2 (ρ z1 + √(1-ρ²) z2)
+// for independent standard normals z1, z2
+func (c *FeatureCorrelation) sample(rng *rand.Rand) (x1, x2 float64) {
+	z1, z2 := rng.NormFloat64(), rng.NormFloat64()
+	s1, s2 := math.Sqrt(orOne(c.VarX1)), math.Sqrt(orOne(c.VarX2))
+	rho := c.Correlation
+	return c.MeanX1 + s1*z1, c.MeanX2 + s2*(rho*z1+math.Sqrt(1-rho*rho)*z2)
+}
+
+func orOne(v float64) float64 {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// DatasetStats2D summarizes the features of a 2D dataset: their empirical
+// moments and the conditioning of the loss they make
+type DatasetStats2D struct {
+	NumPoints   int     `json:"num_points"`
+	MeanX1      float64 `json:"mean_x1"`
+	MeanX2      float64 `json:"mean_x2"`
+	VarX1       float64 `json:"var_x1"`
+	VarX2       float64 `json:"var_x2"`
+	Correlation float64 `json:"correlation"` // Pearson correlation of x1 and x2; 0 when a feature is constant
+
+	// ConditionNumber is λmax/λmin of the loss Hessian 2/n XᵀX (0 when singular).
+	// The model has no intercept, so mean offsets raise it as much as correlation.
+	ConditionNumber float64 `json:"condition_number"`
+}
+
+// SummarizeDataset2D computes the feature statistics of a dataset
+func SummarizeDataset2D(data []DataPoint2D) DatasetStats2D {
+	stats := DatasetStats2D{NumPoints: len(data)}
+	if len(data) == 0 {
+		return stats
+	}
+	n := float64(len(data))
+	for _, p := range data {
+		stats.MeanX1 += p.X1 / n
+		stats.MeanX2 += p.X2 / n
+	}
+	var cov float64
+	for _, p := range data {
+		d1, d2 := p.X1-stats.MeanX1, p.X2-stats.MeanX2
+		stats.VarX1 += d1 * d1 / n
+		stats.VarX2 += d2 * d2 / n
+		cov += d1 * d2 / n
+	}
+	if stats.VarX1 > 0 && stats.VarX2 > 0 {
+		stats.Correlation = cov / math.Sqrt(stats.VarX1*stats.VarX2)
+	}
+	stats.ConditionNumber = AnalyzeHessian(data, 0).ConditionNumber
+	return stats
+}
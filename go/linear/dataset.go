@@ -1,6 +1,8 @@
 package linear
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 )
 
@@ -15,10 +17,14 @@ type DataGenConfig2D struct {
 	TrueW2     float64 `json:"true_w2"`
 	NoiseLevel float64 `json:"noise_level"`
 	Seed       int64   `json:"seed"`
+
+	// Correlated draws (x1, x2) from a bivariate normal instead; the x ranges
+	// are then ignored
+	Correlated *FeatureCorrelation `json:"correlated,omitempty"`
 }
 
 // GenerateRandomData creates a synthetic 2D linear dataset
-// Data follows: y = w1*x1 + w2*x2 + noise
+// Data follows: y = w1*x1 + w2*x2 + noise, with uniform or correlated features
 func GenerateRandomData(config DataGenConfig2D) []DataPoint2D {
 	rng := rand.New(rand.NewSource(config.Seed))
 	data := make([]DataPoint2D, config.NumPoints)
@@ -27,9 +33,14 @@ func GenerateRandomData(config DataGenConfig2D) []DataPoint2D {
 	x2Range := config.X2Max - config.X2Min
 
 	for i := 0; i < config.NumPoints; i++ {
-		// Generate random x1, x2 in specified ranges
-		x1 := config.X1Min + rng.Float64()*x1Range
-		x2 := config.X2Min + rng.Float64()*x2Range
+		// Generate random x1, x2 in specified ranges, or correlated
+		var x1, x2 float64
+		if config.Correlated != nil {
+			x1, x2 = config.Correlated.sample(rng)
+		} else {
+			x1 = config.X1Min + rng.Float64()*x1Range
+			x2 = config.X2Min + rng.Float64()*x2Range
+		}
 
 		// Compute true y value
 		yTrue := config.TrueW1*x1 + config.TrueW2*x2
@@ -47,3 +58,24 @@ func GenerateRandomData(config DataGenConfig2D) []DataPoint2D {
 
 	return data
 }
+
+// ValidateDataset checks if a 2D dataset is valid for training
+func ValidateDataset(data []DataPoint2D) error {
+	if len(data) == 0 {
+		return fmt.Errorf("dataset is empty")
+	}
+
+	for i, point := range data {
+		if math.IsNaN(point.X1) || math.IsInf(point.X1, 0) {
+			return fmt.Errorf("point %d has invalid X1 value: %f", i, point.X1)
+		}
+		if math.IsNaN(point.X2) || math.IsInf(point.X2, 0) {
+			return fmt.Errorf("point %d has invalid X2 value: %f", i, point.X2)
+		}
+		if math.IsNaN(point.YTrue) || math.IsInf(point.YTrue, 0) {
+			return fmt.Errorf("point %d has invalid YTrue value: %f", i, point.YTrue)
+		}
+	}
+
+	return nil
+}
@@ -1,13 +1,19 @@
 package linear
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/expect"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
 )
 
-// CaseConfig2D represents metadata for a Phase 2 case
+// CaseConfig2D represents metadata for a Phase 2 case, declared in a file under cases/
 type CaseConfig2D struct {
 	ID          string           `json:"id"`
 	Name        string           `json:"name"`
@@ -17,6 +23,31 @@ type CaseConfig2D struct {
 	DataConfig  DataGenConfig2D  `json:"data_config"`
 	TrainConfig TrainingConfig2D `json:"training_config"`
 	Insights    []string         `json:"insights"`
+
+	// Overlays are other optimizers trained on the same dataset, drawn over the
+	// case's trajectory on the same loss grid
+	Overlays []Overlay2D `json:"overlays,omitempty"`
+
+	// Race trains other configs on the case's dataset and ranks them
+	Race *Race2D `json:"race,omitempty"`
+
+	// Expect holds the checkable claims of the insights; generation fails when they no longer hold
+	Expect *expect.Expectations `json:"expect,omitempty"`
+}
+
+// Overlay2D is a named training config run next to a case's own
+type Overlay2D struct {
+	Name        string           `json:"name"`
+	TrainConfig TrainingConfig2D `json:"training_config"`
+
+	// Expect holds the checkable claims about this overlay's run
+	Expect *expect.Expectations `json:"expect,omitempty"`
+}
+
+// OverlayRun2D is the Go-trained run of an overlay
+type OverlayRun2D struct {
+	Name      string           `json:"name"`
+	Snapshots []LinearSnapshot `json:"snapshots"`
 }
 
 // CaseManifest2D represents the manifest of all Phase 2 cases
@@ -25,212 +56,43 @@ type CaseManifest2D struct {
 	Cases   []CaseConfig2D `json:"cases"`
 }
 
-// GenerateCases2D generates all pre-computed Phase 2 cases
+// GenerateCases2D writes the Phase 2 manifest and a config.json per case
 func GenerateCases2D(outputDir string) error {
-	cases := []CaseConfig2D{
-		{
-			ID:          "lr-small",
-			Name:        "Learning Rate Too Small",
-			Description: "Tiny steps make convergence painfully slow. Watch gradient descent crawl toward the optimum.",
-			Emoji:       "🐌",
-			Category:    "learning-rate",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      0.0,
-				X1Max:      5.0,
-				X2Min:      0.0,
-				X2Max:      5.0,
-				TrueW1:     2.0,
-				TrueW2:     1.5,
-				NoiseLevel: 0.5,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: 0.0,
-				W2Init: 0.0,
-				LR:     0.0001,
-				MaxSteps: 200,
-			},
-			Insights: []string{
-				"Tiny steps: learning rate = 0.0001",
-				"Converges very slowly, never reaches optimum",
-				"Gradient magnitude stays large even after 200 steps",
-			},
-		},
-		{
-			ID:          "lr-optimal",
-			Name:        "Optimal Learning Rate",
-			Description: "The Goldilocks zone: smooth, efficient steps toward convergence. This is what you want.",
-			Emoji:       "✓",
-			Category:    "learning-rate",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      0.0,
-				X1Max:      5.0,
-				X2Min:      0.0,
-				X2Max:      5.0,
-				TrueW1:     2.0,
-				TrueW2:     1.5,
-				NoiseLevel: 0.5,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: 0.0,
-				W2Init: 0.0,
-				LR:     0.01,
-				MaxSteps: 100,
-			},
-			Insights: []string{
-				"Smooth convergence in ~50 steps",
-				"Gradient magnitude decreases steadily",
-				"Direct path to optimum with minimal oscillation",
-			},
-		},
-		{
-			ID:          "lr-large",
-			Name:        "Learning Rate Too Large",
-			Description: "Big steps overshoot the target. Watch the zigzag pattern as optimization bounces around.",
-			Emoji:       "↔️",
-			Category:    "learning-rate",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      0.0,
-				X1Max:      5.0,
-				X2Min:      0.0,
-				X2Max:      5.0,
-				TrueW1:     2.0,
-				TrueW2:     1.5,
-				NoiseLevel: 0.5,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: 0.0,
-				W2Init: 0.0,
-				LR:     0.1,
-				MaxSteps: 100,
-			},
-			Insights: []string{
-				"Overshooting causes zigzag pattern",
-				"Eventually converges but inefficiently",
-				"Large oscillations in parameter space",
-			},
-		},
-		{
-			ID:          "anisotropic-easy",
-			Name:        "Anisotropic Loss Surface (Mild)",
-			Description: "Different scales in x1 vs x2 create an elliptical loss surface. Faster progress in one direction.",
-			Emoji:       "⬭",
-			Category:    "anisotropy",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      0.0,
-				X1Max:      1.0,
-				X2Min:      0.0,
-				X2Max:      10.0,
-				TrueW1:     2.0,
-				TrueW2:     1.5,
-				NoiseLevel: 0.5,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: 0.0,
-				W2Init: 0.0,
-				LR:     0.01,
-				MaxSteps: 150,
-			},
-			Insights: []string{
-				"Elliptical contours due to different x1/x2 scales",
-				"Faster movement in w2 direction",
-				"Still converges with standard learning rate",
-			},
-		},
-		{
-			ID:          "anisotropic-hard",
-			Name:        "Anisotropic Loss Surface (Extreme)",
-			Description: "Extreme scale difference creates a narrow valley. Optimization struggles with zigzag motion.",
-			Emoji:       "⬬",
-			Category:    "anisotropy",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      0.0,
-				X1Max:      1.0,
-				X2Min:      0.0,
-				X2Max:      20.0,
-				TrueW1:     2.0,
-				TrueW2:     0.5,
-				NoiseLevel: 0.3,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: 0.0,
-				W2Init: 0.0,
-				LR:     0.008,
-				MaxSteps: 200,
-			},
-			Insights: []string{
-				"Very elongated ellipse creates narrow valley",
-				"Zigzag pattern even with reduced learning rate",
-				"Demonstrates why feature scaling matters",
-			},
-		},
-		{
-			ID:          "saddle-point",
-			Name:        "Near-Saddle Geometry",
-			Description: "Starting near a saddle-like region shows curved, non-direct trajectory to optimum.",
-			Emoji:       "〰️",
-			Category:    "geometry",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      -2.0,
-				X1Max:      3.0,
-				X2Min:      -2.0,
-				X2Max:      3.0,
-				TrueW1:     2.0,
-				TrueW2:     1.5,
-				NoiseLevel: 0.5,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: -1.0,
-				W2Init: -1.0,
-				LR:     0.01,
-				MaxSteps: 150,
-			},
-			Insights: []string{
-				"Gradient direction changes rapidly",
-				"Curved trajectory through parameter space",
-				"Starting position affects convergence path",
-			},
-		},
-		{
-			ID:          "zigzag-convergence",
-			Name:        "Zigzag with High LR + Anisotropy",
-			Description: "Combined effect: high learning rate meets anisotropic surface. Maximum zigzag demonstration.",
-			Emoji:       "⚡",
-			Category:    "geometry",
-			DataConfig: DataGenConfig2D{
-				NumPoints:  20,
-				X1Min:      0.0,
-				X1Max:      1.0,
-				X2Min:      0.0,
-				X2Max:      15.0,
-				TrueW1:     2.0,
-				TrueW2:     0.8,
-				NoiseLevel: 0.4,
-				Seed:       42,
-			},
-			TrainConfig: TrainingConfig2D{
-				W1Init: 3.0,
-				W2Init: -1.5,
-				LR:     0.03,
-				MaxSteps: 200,
-			},
-			Insights: []string{
-				"Bouncing back and forth dramatically",
-				"High LR + anisotropy = worst case scenario",
-				"Slow progress despite high learning rate",
-			},
-		},
+	return GenerateCases2DCached(outputDir, nil, false)
+}
+
+// GenerateCases2DCached writes the Phase 2 manifest and a config.json per case,
+// reusing trained results from store. With withSnapshots, each case also gets
+// the Go-trained snapshots.json, a precomputed loss_grid.json, its
+// contours.json and gradient_field.json; snapshot files whose result is cached and which exist are skipped.
+func GenerateCases2DCached(outputDir string, store *cache.Store, withSnapshots bool) error {
+	cases := Cases2D()
+
+	// Phase 2 cases are trained in the browser; train them here too to check
+	// their expectations and to derive the insights of cases without any
+	results := make([][]byte, len(cases))
+	cached := make([]bool, len(cases))
+	for i, caseConfig := range cases {
+		key, err := CaseKey2D(caseConfig)
+		if err != nil {
+			return fmt.Errorf("failed to hash case %s: %w", caseConfig.ID, err)
+		}
+		results[i], cached[i], err = store.GetOrCompute(key, func() (interface{}, error) {
+			return GenerateCaseSnapshots2D(caseConfig)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate case %s: %w", caseConfig.ID, err)
+		}
+		var trained CaseSnapshots2D
+		if err := json.Unmarshal(results[i], &trained); err != nil {
+			return fmt.Errorf("failed to decode case %s: %w", caseConfig.ID, err)
+		}
+		if err := CheckCase2D(caseConfig, trained); err != nil {
+			return err
+		}
+		if len(caseConfig.Insights) == 0 {
+			cases[i].Insights = insight.Messages(insight.Analyze(TraceSnapshots2D(trained.Snapshots)))
+		}
 	}
 
 	// Create manifest
@@ -253,10 +115,18 @@ func GenerateCases2D(outputDir string) error {
 	fmt.Printf("Generated Phase 2 manifest at %s\n", manifestPath)
 
 	// Generate each case
-	for _, caseConfig := range cases {
+	for i, caseConfig := range cases {
 		if err := generateCase(outputDir, caseConfig); err != nil {
 			return fmt.Errorf("failed to generate case %s: %w", caseConfig.ID, err)
 		}
+		if err := generateRace2D(outputDir, caseConfig, store); err != nil {
+			return fmt.Errorf("failed to generate the race of case %s: %w", caseConfig.ID, err)
+		}
+		if withSnapshots {
+			if err := generateCaseSnapshots(outputDir, caseConfig, results[i], cached[i]); err != nil {
+				return fmt.Errorf("failed to generate snapshots of case %s: %w", caseConfig.ID, err)
+			}
+		}
 		fmt.Printf("Generated case: %s\n", caseConfig.ID)
 	}
 
@@ -269,16 +139,220 @@ type CaseConfigJSON struct {
 	Description    string           `json:"description"`
 	DataConfig     DataGenConfig2D  `json:"data_config"`
 	TrainingConfig TrainingConfig2D `json:"training_config"`
+	Overlays       []Overlay2D      `json:"overlays,omitempty"`
+	Race           *Race2D          `json:"race,omitempty"`
 	LossGridConfig LossGridConfig   `json:"loss_grid_config"`
+	ConfigHash     string           `json:"config_hash"` // matches the consistency metadata of Go-trained snapshots
+
+	// Hessian and feature statistics of the Go-generated dataset; the browser
+	// draws its own data, so its values differ slightly
+	Hessian      HessianAnalysis `json:"hessian"`
+	DatasetStats DatasetStats2D  `json:"dataset_stats"`
 }
 
-// LossGridConfig holds parameters for loss grid computation
+// LossGridConfig holds parameters for loss grid computation. All-zero bounds
+// are fitted to the run (see FitLossGridConfig).
 type LossGridConfig struct {
 	W1Min      float64 `json:"w1_min"`
 	W1Max      float64 `json:"w1_max"`
 	W2Min      float64 `json:"w2_min"`
 	W2Max      float64 `json:"w2_max"`
 	Resolution int     `json:"resolution"`
+
+	// Refine > 1 crowds the grid lines towards the optimum, with spacing growing
+	// as the refine-th power of the distance (see BuildLossGrid); up to 4
+	Refine float64 `json:"refine,omitempty"`
+}
+
+// DefaultLossGridConfig returns the grid bounds used when there is nothing to fit
+func DefaultLossGridConfig() LossGridConfig {
+	return LossGridConfig{
+		W1Min:      -1.0,
+		W1Max:      4.0,
+		W2Min:      -1.0,
+		W2Max:      4.0,
+		Resolution: 50,
+	}
+}
+
+// CaseSnapshots2D is a Phase 2 case trained in Go: its dataset and every snapshot
+type CaseSnapshots2D struct {
+	CaseID      string            `json:"case_id"`
+	Consistency Consistency2D     `json:"consistency"`
+	Dataset     []DataPoint2D     `json:"dataset"`
+	Hessian     HessianAnalysis   `json:"hessian"`
+	Trajectory  TrajectoryMetrics `json:"trajectory"`
+	Snapshots   []LinearSnapshot  `json:"snapshots"`
+	Overlays    []OverlayRun2D    `json:"overlays,omitempty"`
+}
+
+// LossGridConfig fits the case's loss grid to its Go dataset, trajectory and
+// overlays. The browser draws its own data, whose run stays close enough to be
+// covered by the margin.
+func (c CaseSnapshots2D) LossGridConfig() LossGridConfig {
+	runs := [][]LinearSnapshot{c.Snapshots}
+	for _, overlay := range c.Overlays {
+		runs = append(runs, overlay.Snapshots)
+	}
+	return FitLossGridConfig(c.Dataset, DefaultLossGridConfig().Resolution, runs...)
+}
+
+// CaseLossGrid2D is the precomputed loss grid of a case's Go-generated dataset
+type CaseLossGrid2D struct {
+	CaseID     string `json:"case_id"`
+	ConfigHash string `json:"config_hash"`
+	LossGrid
+}
+
+// CaseContours2D holds the iso-contours of a case's loss grid, at the default
+// log-spaced levels
+type CaseContours2D struct {
+	CaseID     string    `json:"case_id"`
+	ConfigHash string    `json:"config_hash"`
+	Contours   []Contour `json:"contours"`
+}
+
+// CaseGradientField2D holds the gradient field over a case's loss grid box, with
+// the streamline of the gradient flow from the case's initial weights
+type CaseGradientField2D struct {
+	CaseID     string `json:"case_id"`
+	ConfigHash string `json:"config_hash"`
+	GradientField
+}
+
+// Consistency2D identifies the configs a Phase 2 case run was trained from and
+// summarizes its dataset and result. Go and the browser draw different data
+// from the same config (math/rand versus an LCG), so runs are compared by these
+// statistics rather than point by point.
+type Consistency2D struct {
+	Trainer     string  `json:"trainer"`     // "go" or "browser"
+	ConfigHash  string  `json:"config_hash"` // see ConfigHash2D
+	NumPoints   int     `json:"num_points"`
+	MeanX1      float64 `json:"mean_x1"`
+	MeanX2      float64 `json:"mean_x2"`
+	MeanY       float64 `json:"mean_y"`
+	NumSteps    int     `json:"num_steps"`
+	InitialLoss float64 `json:"initial_loss"`
+	FinalLoss   float64 `json:"final_loss"`
+	FinalW1     float64 `json:"final_w1"`
+	FinalW2     float64 `json:"final_w2"`
+}
+
+// ConfigHash2D returns the hex SHA-256 of the JSON of a case's data and training
+// configs. Unlike CaseKey2D it does not change with the cache format.
+func ConfigHash2D(caseConfig CaseConfig2D) (string, error) {
+	data, err := json.Marshal(struct {
+		DataConfig  DataGenConfig2D  `json:"data_config"`
+		TrainConfig TrainingConfig2D `json:"training_config"`
+	}{caseConfig.DataConfig, caseConfig.TrainConfig})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SummarizeConsistency2D computes the consistency metadata of a Go-trained run
+func SummarizeConsistency2D(configHash string, data []DataPoint2D, snapshots []LinearSnapshot) Consistency2D {
+	c := Consistency2D{
+		Trainer:    "go",
+		ConfigHash: configHash,
+		NumPoints:  len(data),
+		NumSteps:   len(snapshots),
+	}
+	for _, p := range data {
+		c.MeanX1 += p.X1 / float64(len(data))
+		c.MeanX2 += p.X2 / float64(len(data))
+		c.MeanY += p.YTrue / float64(len(data))
+	}
+	if len(snapshots) > 0 {
+		last := snapshots[len(snapshots)-1]
+		c.InitialLoss = snapshots[0].Loss
+		c.FinalLoss = last.Loss
+		c.FinalW1 = last.UpdateComponents.W1New
+		c.FinalW2 = last.UpdateComponents.W2New
+	}
+	return c
+}
+
+// CaseConfigFile returns the minimal config file for client-side training of a case
+func CaseConfigFile(caseConfig CaseConfig2D) CaseConfigJSON {
+	// Training only fails to hash the config, and fixed-layout structs cannot fail to marshal
+	trained, _ := GenerateCaseSnapshots2D(caseConfig)
+	return CaseConfigJSON{
+		Name:           caseConfig.Name,
+		Description:    caseConfig.Description,
+		DataConfig:     caseConfig.DataConfig,
+		TrainingConfig: caseConfig.TrainConfig,
+		Overlays:       caseConfig.Overlays,
+		Race:           caseConfig.Race,
+		LossGridConfig: trained.LossGridConfig(),
+		ConfigHash:     trained.Consistency.ConfigHash,
+		Hessian:        trained.Hessian,
+		DatasetStats:   SummarizeDataset2D(trained.Dataset),
+	}
+}
+
+// CaseKey2D returns the result cache key of a case's snapshots
+func CaseKey2D(caseConfig CaseConfig2D) (string, error) {
+	return cache.Key(cache.KeyInput{
+		Phase:          "phase2-case",
+		DataConfig:     caseConfig.DataConfig,
+		TrainingConfig: caseConfig.TrainConfig,
+		Extra:          caseConfig.Overlays,
+	})
+}
+
+// GenerateCaseSnapshots2D generates the case's dataset and trains on it
+func GenerateCaseSnapshots2D(caseConfig CaseConfig2D) (CaseSnapshots2D, error) {
+	configHash, err := ConfigHash2D(caseConfig)
+	if err != nil {
+		return CaseSnapshots2D{}, err
+	}
+	data := GenerateRandomData(caseConfig.DataConfig)
+	snapshots := RunTraining(data, caseConfig.TrainConfig)
+	var overlays []OverlayRun2D
+	for _, overlay := range caseConfig.Overlays {
+		overlays = append(overlays, OverlayRun2D{
+			Name:      overlay.Name,
+			Snapshots: RunTraining(data, overlay.TrainConfig),
+		})
+	}
+	return CaseSnapshots2D{
+		CaseID:      caseConfig.ID,
+		Consistency: SummarizeConsistency2D(configHash, data, snapshots),
+		Dataset:     data,
+		Hessian:     AnalyzeHessian(data, caseConfig.TrainConfig.LR),
+		Trajectory:  SummarizeTrajectory(snapshots, data),
+		Snapshots:   snapshots,
+		Overlays:    overlays,
+	}, nil
+}
+
+// MeasureSnapshots2D measures a Phase 2 run for checking case expectations
+func MeasureSnapshots2D(snapshots []LinearSnapshot) expect.Metrics {
+	t := TraceSnapshots2D(snapshots)
+	return expect.Measure(t.Loss, t.Params)
+}
+
+// CheckCase2D checks the expectations of a case and its overlays against
+// their snapshots
+func CheckCase2D(caseConfig CaseConfig2D, trained CaseSnapshots2D) error {
+	if err := caseConfig.Expect.Check(MeasureSnapshots2D(trained.Snapshots)); err != nil {
+		return fmt.Errorf("case %s no longer demonstrates its insights:\n%w", caseConfig.ID, err)
+	}
+	for i, overlay := range caseConfig.Overlays {
+		if overlay.Expect == nil {
+			continue
+		}
+		if i >= len(trained.Overlays) || trained.Overlays[i].Name != overlay.Name {
+			return fmt.Errorf("case %s: overlay %s was not trained", caseConfig.ID, overlay.Name)
+		}
+		if err := overlay.Expect.Check(MeasureSnapshots2D(trained.Overlays[i].Snapshots)); err != nil {
+			return fmt.Errorf("case %s overlay %s no longer demonstrates its insights:\n%w", caseConfig.ID, overlay.Name, err)
+		}
+	}
+	return nil
 }
 
 func generateCase(outputDir string, caseConfig CaseConfig2D) error {
@@ -289,19 +363,7 @@ func generateCase(outputDir string, caseConfig CaseConfig2D) error {
 	}
 
 	// Create minimal config file for client-side training
-	config := CaseConfigJSON{
-		Name:           caseConfig.Name,
-		Description:    caseConfig.Description,
-		DataConfig:     caseConfig.DataConfig,
-		TrainingConfig: caseConfig.TrainConfig,
-		LossGridConfig: LossGridConfig{
-			W1Min:      -1.0,
-			W1Max:      4.0,
-			W2Min:      -1.0,
-			W2Max:      4.0,
-			Resolution: 50,
-		},
-	}
+	config := CaseConfigFile(caseConfig)
 
 	// Write config file (~5KB)
 	configPath := filepath.Join(caseDir, "config.json")
@@ -312,6 +374,59 @@ func generateCase(outputDir string, caseConfig CaseConfig2D) error {
 	return nil
 }
 
+// generateCaseSnapshots writes the Go-trained snapshots, the loss grid, its
+// contours and the gradient field of a case. result is the case's CaseSnapshots2D as cached JSON.
+func generateCaseSnapshots(outputDir string, caseConfig CaseConfig2D, result []byte, cached bool) error {
+	caseDir := filepath.Join(outputDir, caseConfig.ID)
+	snapshotsPath := filepath.Join(caseDir, "snapshots.json")
+	lossGridPath := filepath.Join(caseDir, "loss_grid.json")
+	contoursPath := filepath.Join(caseDir, "contours.json")
+	fieldPath := filepath.Join(caseDir, "gradient_field.json")
+	_, snapshotsErr := os.Stat(snapshotsPath)
+	_, lossGridErr := os.Stat(lossGridPath)
+	_, contoursErr := os.Stat(contoursPath)
+	_, fieldErr := os.Stat(fieldPath)
+	if cached && snapshotsErr == nil && lossGridErr == nil && contoursErr == nil && fieldErr == nil {
+		return nil
+	}
+
+	if err := cache.WriteIndented(snapshotsPath, result); err != nil {
+		return fmt.Errorf("failed to write snapshots: %w", err)
+	}
+
+	var trained CaseSnapshots2D
+	if err := json.Unmarshal(result, &trained); err != nil {
+		return fmt.Errorf("failed to decode snapshots: %w", err)
+	}
+	grid := trained.LossGridConfig()
+	lossGrid := CaseLossGrid2D{
+		CaseID:     caseConfig.ID,
+		ConfigHash: trained.Consistency.ConfigHash,
+		LossGrid:   BuildLossGrid(trained.Dataset, grid),
+	}
+	if err := writeJSON(lossGridPath, lossGrid); err != nil {
+		return fmt.Errorf("failed to write loss grid: %w", err)
+	}
+	contours := CaseContours2D{
+		CaseID:     caseConfig.ID,
+		ConfigHash: trained.Consistency.ConfigHash,
+		Contours:   ExtractContours(lossGrid.LossGrid, ContourConfig{}),
+	}
+	if err := writeJSON(contoursPath, contours); err != nil {
+		return fmt.Errorf("failed to write contours: %w", err)
+	}
+	start := [2]float64{caseConfig.TrainConfig.W1Init, caseConfig.TrainConfig.W2Init}
+	field := CaseGradientField2D{
+		CaseID:        caseConfig.ID,
+		ConfigHash:    trained.Consistency.ConfigHash,
+		GradientField: ComputeGradientField(trained.Dataset, grid.W1Min, grid.W1Max, grid.W2Min, grid.W2Max, GradientFieldConfig{Seeds: [][2]float64{start}}),
+	}
+	if err := writeJSON(fieldPath, field); err != nil {
+		return fmt.Errorf("failed to write gradient field: %w", err)
+	}
+	return nil
+}
+
 func writeJSON(path string, data interface{}) error {
 	file, err := os.Create(path)
 	if err != nil {
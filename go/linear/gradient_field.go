@@ -0,0 +1,214 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// Defaults and limits of GradientFieldConfig
+const (
+	DefaultFieldResolution = 20
+	MaxFieldResolution     = 100
+	MaxStreamlineSeeds     = 50
+	DefaultStreamlineSteps = 500
+	MaxStreamlineSteps     = 10000
+)
+
+// Reasons a streamline stops
+const (
+	StopConverged = "converged" // the loss stopped decreasing: the flow reached a minimum
+	StopBoundary  = "boundary"  // the flow left the parameter box
+	StopMaxSteps  = "max_steps" // the steps ran out
+)
+
+// GradientFieldConfig samples the full-batch gradient on a lattice over the
+// parameter box, and traces the gradient flow from seed points
+type GradientFieldConfig struct {
+	Resolution int `json:"resolution,omitempty"` // lattice points per axis (default 20)
+
+	// Seeds start streamlines of the continuous gradient flow dw/dt = -∇L(w)
+	Seeds           [][2]float64 `json:"seeds,omitempty"`
+	StepSize        float64      `json:"step_size,omitempty"`        // largest arc length per step (default 1/200 of the box diagonal)
+	StreamlineSteps int          `json:"streamline_steps,omitempty"` // default 500
+}
+
+// GradientVector is the full-batch gradient at one lattice point
+type GradientVector struct {
+	W1        float64 `json:"w1"`
+	W2        float64 `json:"w2"`
+	GradW1    float64 `json:"grad_w1"`
+	GradW2    float64 `json:"grad_w2"`
+	Magnitude float64 `json:"magnitude"`
+	DirW1     float64 `json:"dir_w1"` // the gradient divided by its magnitude (0 at a stationary point)
+	DirW2     float64 `json:"dir_w2"`
+}
+
+// Streamline is a path of the gradient flow, sampled about evenly by arc length.
+// Gradient descent with learning rate lr approximates the flow at times k·lr.
+type Streamline struct {
+	Seed   [2]float64   `json:"seed"`
+	Points [][2]float64 `json:"points"`
+	Times  []float64    `json:"times"` // flow time t of each point
+	Losses []float64    `json:"losses"`
+	Stop   string       `json:"stop"` // see the Stop constants
+}
+
+// GradientField is the gradient lattice over a parameter box, w1-major like LossGrid
+type GradientField struct {
+	W1Min        float64          `json:"w1_min"`
+	W1Max        float64          `json:"w1_max"`
+	W2Min        float64          `json:"w2_min"`
+	W2Max        float64          `json:"w2_max"`
+	Resolution   int              `json:"resolution"`
+	MaxMagnitude float64          `json:"max_magnitude"` // for scaling arrows
+	Vectors      []GradientVector `json:"vectors"`
+	Streamlines  []Streamline     `json:"streamlines,omitempty"`
+}
+
+// ValidateGradientFieldConfig checks the lattice, seeds and streamline steps of a gradient field config
+func ValidateGradientFieldConfig(config GradientFieldConfig) error {
+	if config.Resolution != 0 && (config.Resolution < 2 || config.Resolution > MaxFieldResolution) {
+		return fmt.Errorf("gradient field resolution must be between 2 and %d, got %d", MaxFieldResolution, config.Resolution)
+	}
+	if len(config.Seeds) > MaxStreamlineSeeds {
+		return fmt.Errorf("at most %d streamline seeds, got %d", MaxStreamlineSeeds, len(config.Seeds))
+	}
+	for _, seed := range config.Seeds {
+		if !isFinite(seed[0]) || !isFinite(seed[1]) {
+			return fmt.Errorf("streamline seeds must be finite")
+		}
+	}
+	if config.StepSize < 0 || !isFinite(config.StepSize) {
+		return fmt.Errorf("streamline step_size must be non-negative")
+	}
+	if config.StreamlineSteps < 0 || config.StreamlineSteps > MaxStreamlineSteps {
+		return fmt.Errorf("streamline_steps must be between 0 and %d", MaxStreamlineSteps)
+	}
+	return nil
+}
+
+// ComputeGradientField samples the gradient on a lattice over the box and
+// traces a streamline from each seed
+func ComputeGradientField(data []DataPoint2D, w1Min, w1Max, w2Min, w2Max float64, config GradientFieldConfig) GradientField {
+	res := config.Resolution
+	if res == 0 {
+		res = DefaultFieldResolution
+	}
+	w1Step := (w1Max - w1Min) / float64(res-1)
+	w2Step := (w2Max - w2Min) / float64(res-1)
+
+	field := GradientField{
+		W1Min:      w1Min,
+		W1Max:      w1Max,
+		W2Min:      w2Min,
+		W2Max:      w2Max,
+		Resolution: res,
+		Vectors:    make([]GradientVector, 0, res*res),
+	}
+	for i := 0; i < res; i++ {
+		w1 := w1Min + float64(i)*w1Step
+		for j := 0; j < res; j++ {
+			w2 := w2Min + float64(j)*w2Step
+			_, g := fullBatchGradient(data, w1, w2)
+			v := GradientVector{W1: w1, W2: w2, GradW1: g[0], GradW2: g[1], Magnitude: GradientMagnitude(g[0], g[1])}
+			if v.Magnitude > 0 {
+				v.DirW1, v.DirW2 = g[0]/v.Magnitude, g[1]/v.Magnitude
+			}
+			field.MaxMagnitude = math.Max(field.MaxMagnitude, v.Magnitude)
+			field.Vectors = append(field.Vectors, v)
+		}
+	}
+
+	for _, seed := range config.Seeds {
+		field.Streamlines = append(field.Streamlines, traceStreamline(data, field, seed, config))
+	}
+	return field
+}
+
+// traceStreamline follows the gradient flow from seed. The loss is quadratic,
+// so the flow has a closed form: in the Hessian's eigenbasis each coordinate
+// decays exponentially at the rate of its eigenvalue towards the optimum, or
+// drifts linearly along a flat direction. Time steps of h/|∇L| keep each step's
+// arc length at most h (|∇L| only shrinks along the flow), so the points are
+// spaced about evenly however flat the loss gets.
+func traceStreamline(data []DataPoint2D, box GradientField, seed [2]float64, config GradientFieldConfig) Streamline {
+	h := config.StepSize
+	if h == 0 {
+		h = math.Hypot(box.W1Max-box.W1Min, box.W2Max-box.W2Min) / 200
+	}
+	maxSteps := config.StreamlineSteps
+	if maxSteps == 0 {
+		maxSteps = DefaultStreamlineSteps
+	}
+	inBox := func(w [2]float64) bool {
+		return w[0] >= box.W1Min && w[0] <= box.W1Max && w[1] >= box.W2Min && w[1] <= box.W2Max
+	}
+
+	// The gradient is Hw - b; in the eigenbasis z = Vw it is λi·zi - ci
+	hessian := AnalyzeHessian(data, 0)
+	v, lambda := hessian.Eigenvectors, hessian.Eigenvalues
+	var b [2]float64
+	for _, p := range data {
+		b[0] += 2 * p.YTrue * p.X1 / float64(len(data))
+		b[1] += 2 * p.YTrue * p.X2 / float64(len(data))
+	}
+	var z0, c [2]float64
+	for i := range v {
+		z0[i] = v[i][0]*seed[0] + v[i][1]*seed[1]
+		c[i] = v[i][0]*b[0] + v[i][1]*b[1]
+	}
+	flow := func(t float64) [2]float64 {
+		var z [2]float64
+		for i := range z {
+			if lambda[i] > 1e-12*math.Max(lambda[0], 1) {
+				target := c[i] / lambda[i]
+				z[i] = target + (z0[i]-target)*math.Exp(-lambda[i]*t)
+			} else {
+				z[i] = z0[i] + c[i]*t
+			}
+		}
+		return [2]float64{v[0][0]*z[0] + v[1][0]*z[1], v[0][1]*z[0] + v[1][1]*z[1]}
+	}
+
+	w, t := seed, 0.0
+	loss, g := fullBatchGradient(data, w[0], w[1])
+	line := Streamline{Seed: seed, Points: [][2]float64{w}, Times: []float64{0}, Losses: []float64{loss}, Stop: StopMaxSteps}
+	for step := 0; step < maxSteps; step++ {
+		magnitude := GradientMagnitude(g[0], g[1])
+		if !(magnitude > 0) {
+			line.Stop = StopConverged
+			return line
+		}
+		next := flow(t + h/magnitude)
+		nextLoss, nextGrad := fullBatchGradient(data, next[0], next[1])
+		if !(nextLoss < loss) || math.Hypot(next[0]-w[0], next[1]-w[1]) < h*1e-3 {
+			line.Stop = StopConverged
+			return line
+		}
+		// Seeds outside the box flow in; a line stops once it flows out
+		if inBox(w) && !inBox(next) {
+			line.Stop = StopBoundary
+			return line
+		}
+		w, t, loss, g = next, t+h/magnitude, nextLoss, nextGrad
+		line.Points = append(line.Points, w)
+		line.Times = append(line.Times, t)
+		line.Losses = append(line.Losses, loss)
+	}
+	return line
+}
+
+// fullBatchGradient returns the mean loss and its gradient over the dataset at (w1, w2)
+func fullBatchGradient(data []DataPoint2D, w1, w2 float64) (loss float64, g [2]float64) {
+	n := float64(len(data))
+	for _, p := range data {
+		loss += Loss(Forward(w1, w2, p.X1, p.X2), p.YTrue) / n
+		g[0] += GradW1(w1, w2, p.X1, p.X2, p.YTrue) / n
+		g[1] += GradW2(w1, w2, p.X1, p.X2, p.YTrue) / n
+	}
+	return loss, g
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
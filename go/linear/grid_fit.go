@@ -0,0 +1,140 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+)
+
+// MaxGridRefine bounds LossGridConfig.Refine
+const MaxGridRefine = 4
+
+// ValidateLossGridConfig checks that the loss grid bounds, resolution and refinement are usable
+func ValidateLossGridConfig(config LossGridConfig) error {
+	if config.Resolution < 2 {
+		return fmt.Errorf("resolution must be at least 2, got %d", config.Resolution)
+	}
+	if config.Refine != 0 && (config.Refine < 1 || config.Refine > MaxGridRefine) {
+		return fmt.Errorf("refine must be between 1 and %d, got %g", MaxGridRefine, config.Refine)
+	}
+	if config.AutoBounds() {
+		return nil
+	}
+	if config.W1Max <= config.W1Min {
+		return fmt.Errorf("w1_max must be greater than w1_min")
+	}
+	if config.W2Max <= config.W2Min {
+		return fmt.Errorf("w2_max must be greater than w2_min")
+	}
+	return nil
+}
+
+// AutoBounds reports whether the config leaves its bounds to be fitted
+func (c LossGridConfig) AutoBounds() bool {
+	return c.W1Min == 0 && c.W1Max == 0 && c.W2Min == 0 && c.W2Max == 0
+}
+
+// FitLossGridConfig returns a grid covering the optimum (when unique) and every
+// point of the runs, with a margin of a fifth of the extent on every side. The
+// box is square, so contours keep their shape, and rounded outward to a tenth
+// of the extent's power of ten. Runs are followed only until they diverge: until
+// their loss exceeds insight.DivergenceFactor times the initial loss.
+func FitLossGridConfig(data []DataPoint2D, resolution int, runs ...[]LinearSnapshot) LossGridConfig {
+	lo, hi := [2]float64{math.Inf(1), math.Inf(1)}, [2]float64{math.Inf(-1), math.Inf(-1)}
+	cover := func(w1, w2 float64) {
+		if !isFinite(w1) || !isFinite(w2) {
+			return
+		}
+		lo = [2]float64{math.Min(lo[0], w1), math.Min(lo[1], w2)}
+		hi = [2]float64{math.Max(hi[0], w1), math.Max(hi[1], w2)}
+	}
+	if w1, w2, ok := Optimum(data); ok {
+		cover(w1, w2)
+	}
+	for _, run := range runs {
+		for i, s := range run {
+			if i > 0 && !(s.Loss <= insight.DivergenceFactor*run[0].Loss) {
+				break
+			}
+			cover(s.W1, s.W2)
+		}
+	}
+	if math.IsInf(lo[0], 1) {
+		config := DefaultLossGridConfig()
+		config.Resolution = resolution
+		return config
+	}
+
+	extent := math.Max(math.Max(hi[0]-lo[0], hi[1]-lo[1]), 0.5)
+	half := extent * (0.5 + 0.2)
+	unit := math.Pow(10, math.Floor(math.Log10(2*half))) / 10
+	var bounds [2][2]float64
+	for k := range bounds {
+		mid := (lo[k] + hi[k]) / 2
+		bounds[k] = [2]float64{math.Floor((mid-half)/unit) * unit, math.Ceil((mid+half)/unit) * unit}
+	}
+	return LossGridConfig{
+		W1Min:      roundTo(bounds[0][0], unit),
+		W1Max:      roundTo(bounds[0][1], unit),
+		W2Min:      roundTo(bounds[1][0], unit),
+		W2Max:      roundTo(bounds[1][1], unit),
+		Resolution: resolution,
+	}
+}
+
+// roundTo removes the float noise of a multiple of unit, so bounds like 0.30000000000000004 print as 0.3
+func roundTo(v, unit float64) float64 {
+	digits := math.Pow(10, math.Max(0, -math.Floor(math.Log10(unit))))
+	return math.Round(v*digits) / digits
+}
+
+// BuildLossGrid computes the loss grid of a config. With Refine > 1, the grid
+// lines crowd towards the optimum (see gridAxis); otherwise they are uniform.
+func BuildLossGrid(data []DataPoint2D, config LossGridConfig) LossGrid {
+	optW1, optW2, ok := Optimum(data)
+	if config.Refine <= 1 || !ok {
+		return ComputeLossGrid(data, config.W1Min, config.W1Max, config.W2Min, config.W2Max, config.Resolution)
+	}
+	w1s := gridAxis(config.W1Min, config.W1Max, optW1, config.Resolution, config.Refine)
+	w2s := gridAxis(config.W2Min, config.W2Max, optW2, config.Resolution, config.Refine)
+	grid := LossGrid{
+		W1Min:      config.W1Min,
+		W1Max:      config.W1Max,
+		W2Min:      config.W2Min,
+		W2Max:      config.W2Max,
+		Resolution: config.Resolution,
+		W1Values:   w1s,
+		W2Values:   w2s,
+		Points:     make([]LossGridPoint, 0, len(w1s)*len(w2s)),
+	}
+	for _, w1 := range w1s {
+		for _, w2 := range w2s {
+			loss, _ := fullBatchGradient(data, w1, w2)
+			grid.Points = append(grid.Points, LossGridPoint{W1: w1, W2: w2, Loss: loss})
+		}
+	}
+	return grid
+}
+
+// gridAxis returns n grid values from lo to hi that pass through center (clamped
+// into the range). On each side of it the k-th of m lines is at fraction
+// (k/m)^refine of the way out, so the spacing grows away from the center.
+func gridAxis(lo, hi, center float64, n int, refine float64) []float64 {
+	center = math.Max(lo, math.Min(hi, center))
+	c := int(math.Round((center - lo) / (hi - lo) * float64(n-1)))
+	values := make([]float64, n)
+	for i := range values {
+		switch {
+		case i < c:
+			values[i] = center - (center-lo)*math.Pow(float64(c-i)/float64(c), refine)
+		case i > c:
+			values[i] = center + (hi-center)*math.Pow(float64(i-c)/float64(n-1-c), refine)
+		default:
+			values[i] = center
+		}
+	}
+	// The endpoints are exact whatever the rounding of the powers
+	values[0], values[n-1] = lo, hi
+	return values
+}
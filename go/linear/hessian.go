@@ -0,0 +1,75 @@
+package linear
+
+import "math"
+
+// HessianAnalysis is the curvature of the MSE loss surface of a dataset. The
+// loss is quadratic in (w1, w2), so its Hessian is the constant 2/n XᵀX and
+// gradient descent multiplies the parameter error along each eigenvector by its
+// own factor 1 - lr·λ per step.
+type HessianAnalysis struct {
+	Hessian         [2][2]float64 `json:"hessian"`
+	Eigenvalues     [2]float64    `json:"eigenvalues"`      // largest first
+	Eigenvectors    [2][2]float64 `json:"eigenvectors"`     // unit vectors; Eigenvectors[i] belongs to Eigenvalues[i]
+	ConditionNumber float64       `json:"condition_number"` // λmax/λmin; 0 when the Hessian is singular
+	MaxStableLR     float64       `json:"max_stable_lr"`    // 2/λmax, above which gradient descent diverges
+
+	// ErrorFactors are the per-step factors 1 - lr·λi of the error along each
+	// eigenvector, set when a learning rate is known. A negative factor flips
+	// the error's sign every step (zigzag); |factor| >= 1 diverges.
+	ErrorFactors []float64 `json:"error_factors,omitempty"`
+}
+
+// AnalyzeHessian computes the Hessian of the MSE loss on data with its
+// eigen-decomposition. A positive lr also sets the error factors.
+func AnalyzeHessian(data []DataPoint2D, lr float64) HessianAnalysis {
+	var h HessianAnalysis
+	if len(data) == 0 {
+		return h
+	}
+	n := float64(len(data))
+	for _, p := range data {
+		h.Hessian[0][0] += 2 * p.X1 * p.X1 / n
+		h.Hessian[0][1] += 2 * p.X1 * p.X2 / n
+		h.Hessian[1][1] += 2 * p.X2 * p.X2 / n
+	}
+	h.Hessian[1][0] = h.Hessian[0][1]
+
+	// Closed form for a symmetric 2×2 matrix: the eigenvalues are
+	// mean ± radius, and the first eigenvector is at angle θ with tan 2θ = 2b/(a-d)
+	a, b, d := h.Hessian[0][0], h.Hessian[0][1], h.Hessian[1][1]
+	mean := (a + d) / 2
+	radius := math.Hypot((a-d)/2, b)
+	h.Eigenvalues = [2]float64{mean + radius, mean - radius}
+	theta := math.Atan2(2*b, a-d) / 2
+	h.Eigenvectors = [2][2]float64{
+		{math.Cos(theta), math.Sin(theta)},
+		{-math.Sin(theta), math.Cos(theta)},
+	}
+
+	if h.Eigenvalues[1] > 1e-12*h.Eigenvalues[0] {
+		h.ConditionNumber = h.Eigenvalues[0] / h.Eigenvalues[1]
+	}
+	if h.Eigenvalues[0] > 0 {
+		h.MaxStableLR = 2 / h.Eigenvalues[0]
+	}
+	if lr > 0 {
+		h.ErrorFactors = []float64{1 - lr*h.Eigenvalues[0], 1 - lr*h.Eigenvalues[1]}
+	}
+	return h
+}
+
+// AnnotateEigenErrors fills the parameter error of each snapshot in the
+// eigenbasis of the Hessian. It is left at 0 when the optimum is not unique.
+func AnnotateEigenErrors(snapshots []LinearSnapshot, data []DataPoint2D) {
+	optW1, optW2, ok := Optimum(data)
+	if !ok {
+		return
+	}
+	v := AnalyzeHessian(data, 0).Eigenvectors
+	for i := range snapshots {
+		s := &snapshots[i]
+		e1, e2 := s.W1-optW1, s.W2-optW2
+		s.ErrorEigen1 = finite(v[0][0]*e1 + v[0][1]*e2)
+		s.ErrorEigen2 = finite(v[1][0]*e1 + v[1][1]*e2)
+	}
+}
@@ -0,0 +1,19 @@
+package linear
+
+import "github.com/iOliverNguyen/ml-viz/go/insight"
+
+// TraceSnapshots2D converts Phase 2 snapshots for the insight analyzer
+func TraceSnapshots2D(snapshots []LinearSnapshot) insight.Trace {
+	t := insight.Trace{
+		Steps:      make([]int, len(snapshots)),
+		Loss:       make([]float64, len(snapshots)),
+		ParamNames: []string{"w1", "w2"},
+		Params:     make([][]float64, len(snapshots)),
+	}
+	for i, snap := range snapshots {
+		t.Steps[i] = snap.Step
+		t.Loss[i] = snap.Loss
+		t.Params[i] = []float64{snap.W1, snap.W2}
+	}
+	return t
+}
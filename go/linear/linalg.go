@@ -0,0 +1,211 @@
+package linear
+
+import (
+	"math"
+	"sort"
+)
+
+// HessianAnalysisN is the curvature of the MSE loss surface of an N-feature
+// dataset, like HessianAnalysis, over the parameter vector w (then b)
+type HessianAnalysisN struct {
+	Hessian         [][]float64 `json:"hessian"`
+	Eigenvalues     []float64   `json:"eigenvalues"`      // largest first
+	Eigenvectors    [][]float64 `json:"eigenvectors"`     // unit vectors; Eigenvectors[i] belongs to Eigenvalues[i]
+	ConditionNumber float64     `json:"condition_number"` // λmax/λmin; 0 when the Hessian is singular
+	MaxStableLR     float64     `json:"max_stable_lr"`    // 2/λmax, above which gradient descent diverges
+	ErrorFactors    []float64   `json:"error_factors,omitempty"`
+}
+
+// HessianN returns the Hessian of the MSE loss on data, 2/n X̃ᵀX̃, where X̃ has
+// a column of ones for the bias when it is learned
+func HessianN(data []DataPointN, bias bool) [][]float64 {
+	if len(data) == 0 {
+		return nil
+	}
+	size := len(features(data[0].X, bias))
+	h := make([][]float64, size)
+	for i := range h {
+		h[i] = make([]float64, size)
+	}
+	n := float64(len(data))
+	for _, p := range data {
+		x := features(p.X, bias)
+		for i := range x {
+			for j := range x {
+				h[i][j] += 2 * x[i] * x[j] / n
+			}
+		}
+	}
+	return h
+}
+
+// AnalyzeHessianN computes the Hessian of the MSE loss on data with its
+// eigen-decomposition. A positive lr also sets the error factors.
+func AnalyzeHessianN(data []DataPointN, bias bool, lr float64) HessianAnalysisN {
+	h := HessianAnalysisN{Hessian: HessianN(data, bias)}
+	if h.Hessian == nil {
+		return h
+	}
+	h.Eigenvalues, h.Eigenvectors = SymmetricEigen(h.Hessian)
+	largest, smallest := h.Eigenvalues[0], h.Eigenvalues[len(h.Eigenvalues)-1]
+	if smallest > 1e-12*largest {
+		h.ConditionNumber = largest / smallest
+	}
+	if largest > 0 {
+		h.MaxStableLR = 2 / largest
+	}
+	if lr > 0 {
+		for _, v := range h.Eigenvalues {
+			h.ErrorFactors = append(h.ErrorFactors, 1-lr*v)
+		}
+	}
+	return h
+}
+
+// OptimumN returns the least-squares parameter vector (w, then b when bias is
+// learned) from the normal equations. ok is false when it is not unique.
+func OptimumN(data []DataPointN, bias bool) (params []float64, ok bool) {
+	h := HessianN(data, bias)
+	if h == nil {
+		return nil, false
+	}
+	rhs := make([]float64, len(h))
+	n := float64(len(data))
+	for _, p := range data {
+		for i, x := range features(p.X, bias) {
+			rhs[i] += 2 * x * p.YTrue / n
+		}
+	}
+	return solve(h, rhs)
+}
+
+// SymmetricEigen returns the eigenvalues of a symmetric matrix, largest first,
+// and their unit eigenvectors, using cyclic Jacobi rotations
+func SymmetricEigen(m [][]float64) (values []float64, vectors [][]float64) {
+	size := len(m)
+	a := make([][]float64, size)
+	v := make([][]float64, size) // columns are the eigenvectors
+	for i := range a {
+		a[i] = append([]float64(nil), m[i]...)
+		v[i] = make([]float64, size)
+		v[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off, total := 0.0, 0.0
+		for i := range a {
+			for j := range a {
+				total += a[i][j] * a[i][j]
+				if i != j {
+					off += a[i][j] * a[i][j]
+				}
+			}
+		}
+		if off <= 1e-24*total {
+			break
+		}
+		for p := 0; p < size; p++ {
+			for q := p + 1; q < size; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				// Rotate rows and columns p and q to zero a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < size; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < size; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < size; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	order := make([]int, size)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return a[order[i]][order[i]] > a[order[j]][order[j]] })
+	values = make([]float64, size)
+	vectors = make([][]float64, size)
+	for i, col := range order {
+		values[i] = a[col][col]
+		vectors[i] = make([]float64, size)
+		// Point each eigenvector so its largest component is positive
+		largest := 0
+		for k := 0; k < size; k++ {
+			vectors[i][k] = v[k][col]
+			if math.Abs(v[k][col]) > math.Abs(v[largest][col]) {
+				largest = k
+			}
+		}
+		if vectors[i][largest] < 0 {
+			for k := range vectors[i] {
+				vectors[i][k] = -vectors[i][k]
+			}
+		}
+	}
+	return values, vectors
+}
+
+// solve solves a·x = b by Gaussian elimination with partial pivoting. ok is
+// false when a is (nearly) singular.
+func solve(a [][]float64, b []float64) (x []float64, ok bool) {
+	size := len(a)
+	m := make([][]float64, size)
+	scale := 0.0
+	for i := range a {
+		m[i] = append(append([]float64(nil), a[i]...), b[i])
+		for _, v := range a[i] {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	for col := 0; col < size; col++ {
+		pivot := col
+		for row := col + 1; row < size; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) <= 1e-12*scale {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := col + 1; row < size; row++ {
+			f := m[row][col] / m[col][col]
+			for k := col; k <= size; k++ {
+				m[row][k] -= f * m[col][k]
+			}
+		}
+	}
+	x = make([]float64, size)
+	for row := size - 1; row >= 0; row-- {
+		sum := m[row][size]
+		for k := row + 1; k < size; k++ {
+			sum -= m[row][k] * x[k]
+		}
+		x[row] = sum / m[row][row]
+	}
+	return x, true
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func norm(v []float64) float64 {
+	return math.Sqrt(dot(v, v))
+}
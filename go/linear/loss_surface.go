@@ -15,6 +15,11 @@ type LossGrid struct {
 	W2Max      float64          `json:"w2_max"`
 	Resolution int              `json:"resolution"`
 	Points     []LossGridPoint  `json:"points"`
+
+	// Grid lines of a refined grid (see BuildLossGrid), which are not evenly
+	// spaced; uniform grids omit them
+	W1Values   []float64        `json:"w1_values,omitempty"`
+	W2Values   []float64        `json:"w2_values,omitempty"`
 }
 
 // ComputeLossGrid generates a grid of loss values for contour plotting
@@ -0,0 +1,230 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/iOliverNguyen/ml-viz/go/linesearch"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+)
+
+// Optimizers of TrainingConfig2D.Optimizer
+const (
+	OptimizerGD           = "gd"            // w -= lr·∇L (the default)
+	OptimizerNewton       = "newton"        // w -= H⁻¹∇L
+	OptimizerDampedNewton = "damped-newton" // w -= (H + μI)⁻¹∇L with μ = damping
+	OptimizerGaussNewton  = "gauss-newton"  // w -= (2/n JᵀJ)⁻¹∇L with J the Jacobian of the residuals
+	OptimizerBFGS         = "bfgs"          // w -= B∇L, B an inverse-Hessian estimate starting at lr·I
+	OptimizerCG           = "cg"            // w += αd, d = -∇L + βd_prev (Fletcher-Reeves), α exact
+	OptimizerCoordinate   = "coordinate"    // w_i -= ∂L/∂w_i / H_ii, cycling through w1 and w2
+)
+
+// ValidateTrainingConfig2D checks that the optimizer is known and has the settings it needs
+func ValidateTrainingConfig2D(config TrainingConfig2D) error {
+	switch config.Optimizer {
+	case "", OptimizerGD, OptimizerBFGS:
+		if config.LR <= 0 {
+			return fmt.Errorf("lr must be positive")
+		}
+	case OptimizerNewton, OptimizerGaussNewton, OptimizerCG, OptimizerCoordinate:
+	case OptimizerDampedNewton:
+		if config.Damping <= 0 {
+			return fmt.Errorf("damping must be positive for %s", OptimizerDampedNewton)
+		}
+	default:
+		return fmt.Errorf("unknown optimizer %q (want %s, %s, %s, %s, %s, %s or %s)", config.Optimizer,
+			OptimizerGD, OptimizerNewton, OptimizerDampedNewton, OptimizerGaussNewton, OptimizerBFGS,
+			OptimizerCG, OptimizerCoordinate)
+	}
+	if config.Damping < 0 {
+		return fmt.Errorf("damping must be non-negative")
+	}
+	if config.BatchSize > 0 && config.Optimizer != "" && config.Optimizer != OptimizerGD {
+		return fmt.Errorf("batch_size needs the %s optimizer", OptimizerGD)
+	}
+	if err := minibatch.Validate(config.BatchSize, config.LineSearch.Active()); err != nil {
+		return err
+	}
+	return config.LineSearch.Validate(true)
+}
+
+// optimizer2D computes the update of each step. It keeps the BFGS and
+// conjugate gradient state.
+type optimizer2D struct {
+	config TrainingConfig2D
+	steps  int
+
+	inverse      [2][2]float64 // BFGS inverse-Hessian estimate
+	prevW, prevG [2]float64
+	hasPrevious  bool
+
+	prevD [2]float64 // last conjugate gradient direction
+}
+
+// update2D is one step: the parameters move by StepLength·Direction
+type update2D struct {
+	Direction  [2]float64
+	StepLength float64
+	Beta       float64 // conjugacy coefficient of cg
+	Coordinate int     // coordinate moved by coordinate descent: 1 for w1, 2 for w2
+	Inverse    *[2][2]float64
+}
+
+// Delta returns the parameter change of the update
+func (u update2D) Delta() [2]float64 {
+	return [2]float64{u.StepLength * u.Direction[0], u.StepLength * u.Direction[1]}
+}
+
+func newOptimizer2D(config TrainingConfig2D) *optimizer2D {
+	return &optimizer2D{
+		config:  config,
+		inverse: [2][2]float64{{config.LR, 0}, {0, config.LR}},
+	}
+}
+
+// step returns the update for parameters w with gradient g. hessian is the
+// exact Hessian and gaussNewton the Gauss-Newton matrix 2/n JᵀJ; for this
+// linear model they are equal, so Gauss-Newton matches Newton step for step.
+// BFGS also returns its current inverse-Hessian estimate.
+func (o *optimizer2D) step(w, g [2]float64, hessian, gaussNewton [2][2]float64) update2D {
+	defer func() { o.steps++ }()
+	gradient := update2D{Direction: [2]float64{-g[0], -g[1]}, StepLength: o.config.LR}
+	switch o.config.Optimizer {
+	case OptimizerNewton:
+		return newtonStep(hessian, g, gradient)
+	case OptimizerGaussNewton:
+		return newtonStep(gaussNewton, g, gradient)
+	case OptimizerDampedNewton:
+		mu := o.config.Damping
+		damped := [2][2]float64{{hessian[0][0] + mu, hessian[0][1]}, {hessian[1][0], hessian[1][1] + mu}}
+		return newtonStep(damped, g, gradient)
+	case OptimizerBFGS:
+		o.updateBFGS(w, g)
+		b := o.inverse
+		return update2D{
+			Direction:  [2]float64{-(b[0][0]*g[0] + b[0][1]*g[1]), -(b[1][0]*g[0] + b[1][1]*g[1])},
+			StepLength: 1,
+			Inverse:    &b,
+		}
+	case OptimizerCG:
+		return o.conjugateStep(g, hessian)
+	case OptimizerCoordinate:
+		// Cycle w1, w2, w1, ... and minimize exactly along the axis
+		i := o.steps % 2
+		var d [2]float64
+		d[i] = -g[i]
+		return update2D{Direction: d, StepLength: exactStep(g, d, hessian, o.config.LR), Coordinate: i + 1}
+	}
+	return gradient
+}
+
+// conjugateStep returns the Fletcher-Reeves conjugate gradient update
+// d = -g + βd_prev with β = |g|²/|g_prev|², restarting at -g every 2 steps (the
+// dimension) or when d is not a descent direction. With exact steps on a
+// quadratic it reaches the optimum in 2 steps.
+func (o *optimizer2D) conjugateStep(g [2]float64, hessian [2][2]float64) update2D {
+	d := [2]float64{-g[0], -g[1]}
+	beta := 0.0
+	if o.steps%2 != 0 {
+		if prev := o.prevG[0]*o.prevG[0] + o.prevG[1]*o.prevG[1]; prev > 0 {
+			beta = (g[0]*g[0] + g[1]*g[1]) / prev
+		}
+		conjugate := [2]float64{d[0] + beta*o.prevD[0], d[1] + beta*o.prevD[1]}
+		if conjugate[0]*g[0]+conjugate[1]*g[1] < 0 {
+			d = conjugate
+		} else {
+			beta = 0
+		}
+	}
+	o.prevG, o.prevD = g, d
+	return update2D{Direction: d, StepLength: exactStep(g, d, hessian, o.config.LR), Beta: beta}
+}
+
+// exactStep returns the step length α minimizing the quadratic loss along d:
+// α = -gᵀd / dᵀHd. It returns fallback when the loss is not curved along d.
+func exactStep(g, d [2]float64, hessian [2][2]float64, fallback float64) float64 {
+	slope := g[0]*d[0] + g[1]*d[1]
+	if slope == 0 {
+		return 0
+	}
+	curvature := d[0]*(hessian[0][0]*d[0]+hessian[0][1]*d[1]) + d[1]*(hessian[1][0]*d[0]+hessian[1][1]*d[1])
+	if !(curvature > 0) {
+		return fallback
+	}
+	return -slope / curvature
+}
+
+// updateBFGS folds the last step s and gradient change y into the inverse-Hessian
+// estimate: B ← (I - ρsyᵀ) B (I - ρysᵀ) + ρssᵀ with ρ = 1/yᵀs. Steps without
+// positive curvature (yᵀs ≤ 0) would break positive definiteness and are skipped.
+func (o *optimizer2D) updateBFGS(w, g [2]float64) {
+	defer func() { o.prevW, o.prevG, o.hasPrevious = w, g, true }()
+	if !o.hasPrevious {
+		return
+	}
+	s := [2]float64{w[0] - o.prevW[0], w[1] - o.prevW[1]}
+	y := [2]float64{g[0] - o.prevG[0], g[1] - o.prevG[1]}
+	ys := y[0]*s[0] + y[1]*s[1]
+	if !(ys > 1e-12*math.Hypot(y[0], y[1])*math.Hypot(s[0], s[1])) {
+		return
+	}
+	rho := 1 / ys
+	var left [2][2]float64 // I - ρsyᵀ
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			left[i][j] = -rho * s[i] * y[j]
+		}
+		left[i][i]++
+	}
+	var next [2][2]float64
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			// (left · B · leftᵀ)[i][j], since I - ρysᵀ = leftᵀ
+			for k := 0; k < 2; k++ {
+				for l := 0; l < 2; l++ {
+					next[i][j] += left[i][k] * o.inverse[k][l] * left[j][l]
+				}
+			}
+			next[i][j] += rho * s[i] * s[j]
+		}
+	}
+	o.inverse = next
+}
+
+// searchLine runs a line search along the update's direction from w, starting
+// from the update's own step length
+func searchLine(data []DataPoint2D, config *linesearch.Config, w [2]float64, loss float64, g [2]float64, hessian [2][2]float64, update update2D) linesearch.Result {
+	d := update.Direction
+	hd := [2]float64{hessian[0][0]*d[0] + hessian[0][1]*d[1], hessian[1][0]*d[0] + hessian[1][1]*d[1]}
+	return linesearch.Search(config, linesearch.Line{
+		Loss:      loss,
+		Slope:     g[0]*d[0] + g[1]*d[1],
+		Curvature: d[0]*hd[0] + d[1]*hd[1],
+		Eval: func(step float64) (float64, float64) {
+			trialLoss, trialGrad := fullBatchGradient(data, w[0]+step*d[0], w[1]+step*d[1])
+			return trialLoss, trialGrad[0]*d[0] + trialGrad[1]*d[1]
+		},
+	}, update.StepLength)
+}
+
+// newtonStep returns the full step along -m⁻¹g, or the fallback when m is singular
+func newtonStep(m [2][2]float64, g [2]float64, fallback update2D) update2D {
+	d, ok := NewtonDirection(m, g)
+	if !ok {
+		return fallback
+	}
+	return update2D{Direction: d, StepLength: 1}
+}
+
+// NewtonDirection returns -m⁻¹g. ok is false when m is (nearly) singular.
+func NewtonDirection(m [2][2]float64, g [2]float64) (d [2]float64, ok bool) {
+	det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	scale := math.Max(math.Abs(m[0][0]*m[1][1]), math.Abs(m[0][1]*m[1][0]))
+	if !(math.Abs(det) > 1e-12*scale) {
+		return d, false
+	}
+	return [2]float64{
+		-(m[1][1]*g[0] - m[0][1]*g[1]) / det,
+		-(m[0][0]*g[1] - m[1][0]*g[0]) / det,
+	}, true
+}
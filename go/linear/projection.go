@@ -0,0 +1,214 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+)
+
+// Projection maps parameter vectors (w, then b when the bias is learned) onto
+// the plane through Origin spanned by two orthonormal directions. Coordinates
+// on the plane play the part of (w1, w2), so N-feature runs can be shown with
+// the Phase 2 contour and trajectory views.
+type Projection struct {
+	Origin []float64 `json:"origin"`
+	Dir1   []float64 `json:"dir1"`
+	Dir2   []float64 `json:"dir2"`
+}
+
+// ProjectionConfig chooses a projection plane. Omitted directions are the
+// steepest and flattest Hessian axes; an omitted origin is the least-squares
+// optimum, or the last parameters of the run when the optimum is not unique.
+type ProjectionConfig struct {
+	Origin []float64 `json:"origin,omitempty"`
+	Dir1   []float64 `json:"dir1,omitempty"`
+	Dir2   []float64 `json:"dir2,omitempty"`
+}
+
+// NewProjection returns the projection onto the plane through origin spanned by
+// dir1 and dir2. The directions are orthonormalized, dir1 keeping its direction.
+func NewProjection(origin, dir1, dir2 []float64) (Projection, error) {
+	if len(dir1) != len(origin) || len(dir2) != len(origin) {
+		return Projection{}, fmt.Errorf("directions have %d and %d components for %d parameters", len(dir1), len(dir2), len(origin))
+	}
+	n1 := norm(dir1)
+	if !(n1 > 0) || math.IsInf(n1, 0) {
+		return Projection{}, fmt.Errorf("dir1 must be a finite, nonzero vector")
+	}
+	d1 := make([]float64, len(dir1))
+	for i := range dir1 {
+		d1[i] = dir1[i] / n1
+	}
+	// Gram-Schmidt: remove the dir1 component of dir2
+	along := dot(dir2, d1)
+	d2 := make([]float64, len(dir2))
+	for i := range dir2 {
+		d2[i] = dir2[i] - along*d1[i]
+	}
+	n2 := norm(d2)
+	if !(n2 > 1e-9*norm(dir2)) || math.IsInf(n2, 0) {
+		return Projection{}, fmt.Errorf("dir2 must be a finite vector not parallel to dir1")
+	}
+	for i := range d2 {
+		d2[i] /= n2
+	}
+	return Projection{Origin: append([]float64(nil), origin...), Dir1: d1, Dir2: d2}, nil
+}
+
+// HessianProjection returns the projection through origin onto the steepest and
+// flattest Hessian axes, the plane where the loss surface is most elongated
+func HessianProjection(data []DataPointN, bias bool, origin []float64) (Projection, error) {
+	vectors := AnalyzeHessianN(data, bias, 0).Eigenvectors
+	if len(vectors) < 2 {
+		return Projection{}, fmt.Errorf("a projection needs at least 2 parameters, got %d", len(vectors))
+	}
+	return NewProjection(origin, vectors[0], vectors[len(vectors)-1])
+}
+
+// ResolveProjection builds the projection a config describes for a run. A
+// given origin must have a component per feature, plus one for b when the bias
+// is learned. Without a learned bias b must stay 0, since the plane has no b axis.
+func ResolveProjection(config ProjectionConfig, data []DataPointN, bias bool, snapshots []SnapshotN) (Projection, error) {
+	if len(data) == 0 {
+		return Projection{}, fmt.Errorf("a projection needs data")
+	}
+	if !bias && len(snapshots) > 0 && snapshots[0].B != 0 {
+		return Projection{}, fmt.Errorf("b_init must be 0 without bias, got %g: the projection has no b axis (set bias to learn it)", snapshots[0].B)
+	}
+	numParams := len(data[0].X)
+	if bias {
+		numParams++
+	}
+	if config.Origin != nil && len(config.Origin) != numParams {
+		return Projection{}, fmt.Errorf("origin has %d components for %d parameters", len(config.Origin), numParams)
+	}
+	origin := config.Origin
+	if origin == nil {
+		var ok bool
+		if origin, ok = OptimumN(data, bias); !ok {
+			if len(snapshots) == 0 {
+				return Projection{}, fmt.Errorf("no origin: the optimum is not unique and the run is empty")
+			}
+			origin = snapshots[len(snapshots)-1].Params(bias)
+		}
+	}
+	if (config.Dir1 == nil) != (config.Dir2 == nil) {
+		return Projection{}, fmt.Errorf("set both dir1 and dir2, or neither for the Hessian axes")
+	}
+	if config.Dir1 == nil {
+		return HessianProjection(data, bias, origin)
+	}
+	return NewProjection(origin, config.Dir1, config.Dir2)
+}
+
+// Coords returns the plane coordinates of the orthogonal projection of params
+func (p Projection) Coords(params []float64) (a, b float64) {
+	offset := make([]float64, len(params))
+	for i := range params {
+		offset[i] = params[i] - p.Origin[i]
+	}
+	return dot(offset, p.Dir1), dot(offset, p.Dir2)
+}
+
+// Params returns the parameter vector at plane coordinates (a, b)
+func (p Projection) Params(a, b float64) []float64 {
+	params := make([]float64, len(p.Origin))
+	for i := range params {
+		params[i] = p.Origin[i] + a*p.Dir1[i] + b*p.Dir2[i]
+	}
+	return params
+}
+
+// SliceDataset returns the 2D dataset whose loss at (w1, w2) is the N-feature
+// loss at Params(w1, w2): on the plane, x̃·θ = x̃·origin + w1 (x̃·dir1) + w2 (x̃·dir2),
+// so x1 = x̃·dir1, x2 = x̃·dir2 and y = y - x̃·origin. Every Phase 2 tool (loss
+// grids, Hessian, trajectory metrics) then works on the slice unchanged.
+func (p Projection) SliceDataset(data []DataPointN, bias bool) []DataPoint2D {
+	slice := make([]DataPoint2D, len(data))
+	for i, point := range data {
+		x := features(point.X, bias)
+		slice[i] = DataPoint2D{
+			X1:    dot(x, p.Dir1),
+			X2:    dot(x, p.Dir2),
+			YTrue: point.YTrue - dot(x, p.Origin),
+		}
+	}
+	return slice
+}
+
+// LossGrid computes the loss grid of the plane
+func (p Projection) LossGrid(data []DataPointN, bias bool, grid LossGridConfig) LossGrid {
+	return BuildLossGrid(p.SliceDataset(data, bias), grid)
+}
+
+// Snapshots projects an N-feature run onto the plane as Phase 2 snapshots.
+// Weights and gradients are the plane components, the loss is the full loss,
+// and the point details are those of the slice dataset. Trajectory metrics and
+// eigenbasis errors refer to the slice.
+func (p Projection) Snapshots(data []DataPointN, bias bool, lr float64, snapshots []SnapshotN) []LinearSnapshot {
+	slice := p.SliceDataset(data, bias)
+	hessian := AnalyzeHessian(slice, 0).Hessian
+	projected := make([]LinearSnapshot, len(snapshots))
+	for i, s := range snapshots {
+		params, grad := s.Params(bias), s.Grad(bias)
+		w1, w2 := p.Coords(params)
+		gradW1, gradW2 := dot(grad, p.Dir1), dot(grad, p.Dir2)
+
+		pointDetails := make([]PointSnapshot2D, len(data))
+		for j, point := range data {
+			yPred := ForwardN(s.W, s.B, point.X)
+			residual := yPred - point.YTrue
+			pointDetails[j] = PointSnapshot2D{
+				X1:        slice[j].X1,
+				X2:        slice[j].X2,
+				YTrue:     slice[j].YTrue,
+				YPred:     yPred - dot(features(point.X, bias), p.Origin),
+				PointLoss: residual * residual,
+				GradW1:    2 * residual * slice[j].X1,
+				GradW2:    2 * residual * slice[j].X2,
+			}
+		}
+
+		deltaW1, deltaW2 := -lr*gradW1, -lr*gradW2
+		newton, _ := NewtonDirection(hessian, [2]float64{gradW1, gradW2})
+		projected[i] = LinearSnapshot{
+			Step:              s.Step,
+			W1:                w1,
+			W2:                w2,
+			GradW1:            gradW1,
+			GradW2:            gradW2,
+			Loss:              s.Loss,
+			GradientMagnitude: GradientMagnitude(gradW1, gradW2),
+			GradientDirection: GradientDirection(gradW1, gradW2),
+			PointDetails:      pointDetails,
+			Hessian:           hessian,
+			NewtonDirection:   newton,
+			SearchDirection:   [2]float64{-gradW1, -gradW2},
+			StepLength:        lr,
+			UpdateComponents: UpdateDetails2D{
+				W1Old:   w1,
+				W2Old:   w2,
+				LR:      lr,
+				GradW1:  gradW1,
+				GradW2:  gradW2,
+				DeltaW1: deltaW1,
+				DeltaW2: deltaW2,
+				W1New:   w1 + deltaW1,
+				W2New:   w2 + deltaW2,
+			},
+		}
+	}
+	AnnotateTrajectory(projected, slice)
+	AnnotateEigenErrors(projected, slice)
+	return projected
+}
+
+// FeatureRun is the N-dimensional side of an N-feature run shown on a projection plane
+type FeatureRun struct {
+	NumFeatures int              `json:"num_features"`
+	Bias        bool             `json:"bias"`
+	Dataset     []DataPointN     `json:"dataset"`
+	Optimum     []float64        `json:"optimum,omitempty"` // omitted when not unique
+	Hessian     HessianAnalysisN `json:"hessian"`
+	Projection  Projection       `json:"projection"`
+	Snapshots   []SnapshotN      `json:"snapshots"`
+}
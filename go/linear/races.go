@@ -0,0 +1,106 @@
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+	"github.com/iOliverNguyen/ml-viz/go/race"
+)
+
+// Race2D is a comparison case: named training configs run on the case's
+// dataset, and the criterion that picks the winner. Unlike overlays, which are
+// drawn over the case's trajectory, race runs are ranked against each other.
+type Race2D struct {
+	Winner race.Criterion `json:"winner"`
+	Runs   []Overlay2D    `json:"runs"`
+}
+
+// CaseRace2D is the generated race of a case, trained on its Go dataset
+type CaseRace2D struct {
+	CaseID     string `json:"case_id"`
+	ConfigHash string `json:"config_hash"`
+	race.Result
+}
+
+// Validate checks the criterion and every run. A nil race is valid.
+func (r *Race2D) Validate() error {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, len(r.Runs))
+	for i, run := range r.Runs {
+		names[i] = run.Name
+		if err := validateTrainConfig(run.TrainConfig); err != nil {
+			return fmt.Errorf("run %s: training_config: %w", run.Name, err)
+		}
+	}
+	return r.Winner.Validate(names)
+}
+
+// RunRace2D generates the case's dataset, trains every run of its race on it and ranks them
+func RunRace2D(caseConfig CaseConfig2D) (CaseRace2D, error) {
+	configHash, err := ConfigHash2D(caseConfig)
+	if err != nil {
+		return CaseRace2D{}, err
+	}
+	data := GenerateRandomData(caseConfig.DataConfig)
+	names := make([]string, len(caseConfig.Race.Runs))
+	traces := make([]insight.Trace, len(caseConfig.Race.Runs))
+	for i, run := range caseConfig.Race.Runs {
+		names[i] = run.Name
+		traces[i] = TraceSnapshots2D(RunTraining(data, run.TrainConfig))
+	}
+	return CaseRace2D{
+		CaseID:     caseConfig.ID,
+		ConfigHash: configHash,
+		Result:     race.Compare(caseConfig.Race.Winner, names, traces),
+	}, nil
+}
+
+// RaceKey2D returns the result cache key of a case's race
+func RaceKey2D(caseConfig CaseConfig2D) (string, error) {
+	return cache.Key(cache.KeyInput{
+		Phase:          "phase2-race",
+		DataConfig:     caseConfig.DataConfig,
+		TrainingConfig: caseConfig.TrainConfig, // part of the config hash
+		Extra:          caseConfig.Race,
+	})
+}
+
+// generateRace2D runs the race of a case, if it has one, checks its expected
+// winner and writes race.json next to the case's config
+func generateRace2D(outputDir string, caseConfig CaseConfig2D, store *cache.Store) error {
+	if caseConfig.Race == nil {
+		return nil
+	}
+	key, err := RaceKey2D(caseConfig)
+	if err != nil {
+		return fmt.Errorf("failed to hash race: %w", err)
+	}
+	data, hit, err := store.GetOrCompute(key, func() (interface{}, error) {
+		return RunRace2D(caseConfig)
+	})
+	if err != nil {
+		return err
+	}
+	var result CaseRace2D
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to decode race: %w", err)
+	}
+	if err := caseConfig.Race.Winner.Check(result.Result); err != nil {
+		return fmt.Errorf("case %s no longer demonstrates its insights:\n%w", caseConfig.ID, err)
+	}
+
+	racePath := filepath.Join(outputDir, caseConfig.ID, "race.json")
+	if _, statErr := os.Stat(racePath); hit && statErr == nil {
+		return nil
+	}
+	if err := cache.WriteIndented(racePath, data); err != nil {
+		return fmt.Errorf("failed to write race: %w", err)
+	}
+	return nil
+}
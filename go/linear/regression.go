@@ -0,0 +1,197 @@
+package linear
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// DataPointN is a data point with any number of features
+type DataPointN struct {
+	X     []float64 `json:"x"`
+	YTrue float64   `json:"y_true"`
+}
+
+// DataGenConfigN holds configuration for N-feature dataset generation
+type DataGenConfigN struct {
+	NumPoints     int       `json:"num_points"`
+	XMin          float64   `json:"x_min"` // every feature is drawn from [XMin, XMax] ...
+	XMax          float64   `json:"x_max"`
+	FeatureScales []float64 `json:"feature_scales,omitempty"` // ... times its scale (default 1), which stretches the loss surface
+	TrueW         []float64 `json:"true_w"`                   // one weight per feature
+	TrueB         float64   `json:"true_b"`
+	NoiseLevel    float64   `json:"noise_level"`
+	Seed          int64     `json:"seed"`
+}
+
+// TrainingConfigN holds configuration for N-feature training
+type TrainingConfigN struct {
+	WInit    []float64 `json:"w_init,omitempty"` // defaults to zeros
+	BInit    float64   `json:"b_init"`
+	Bias     bool      `json:"bias"` // learn the intercept b; otherwise b stays at BInit
+	LR       float64   `json:"lr"`
+	MaxSteps int       `json:"max_steps"`
+}
+
+// SnapshotN captures the state of an N-feature model at one training step.
+// Per-point details are left out; project the run to get them on a plane.
+type SnapshotN struct {
+	Step              int       `json:"step"`
+	W                 []float64 `json:"w"`
+	B                 float64   `json:"b"`
+	GradW             []float64 `json:"grad_w"`
+	GradB             float64   `json:"grad_b"` // 0 unless the bias is learned
+	Loss              float64   `json:"loss"`
+	GradientMagnitude float64   `json:"gradient_magnitude"`
+}
+
+// Params returns the parameter vector of the snapshot: w, then b when the bias is learned
+func (s SnapshotN) Params(bias bool) []float64 {
+	return paramVector(s.W, s.B, bias)
+}
+
+// Grad returns the gradient in the layout of Params
+func (s SnapshotN) Grad(bias bool) []float64 {
+	return paramVector(s.GradW, s.GradB, bias)
+}
+
+func paramVector(w []float64, b float64, bias bool) []float64 {
+	v := append([]float64(nil), w...)
+	if bias {
+		v = append(v, b)
+	}
+	return v
+}
+
+// features returns the inputs multiplying the parameter vector: x, then 1 for the bias
+func features(x []float64, bias bool) []float64 {
+	return paramVector(x, 1, bias)
+}
+
+// ForwardN computes the prediction y_pred = w·x + b
+func ForwardN(w []float64, b float64, x []float64) float64 {
+	return dot(w, x) + b
+}
+
+// GradientN returns the mean squared error over data and its gradient, in vector form:
+//
+//	∇w = 2/n Xᵀ(Xw + b - y),  ∂b = 2/n Σ(Xw + b - y)
+func GradientN(data []DataPointN, w []float64, b float64) (loss float64, gradW []float64, gradB float64) {
+	gradW = make([]float64, len(w))
+	n := float64(len(data))
+	for _, p := range data {
+		residual := ForwardN(w, b, p.X) - p.YTrue
+		loss += residual * residual / n
+		for j, x := range p.X {
+			gradW[j] += 2 * residual * x / n
+		}
+		gradB += 2 * residual / n
+	}
+	return loss, gradW, gradB
+}
+
+// GenerateRandomDataN creates a synthetic N-feature linear dataset
+// Data follows: y = w·x + b + noise
+func GenerateRandomDataN(config DataGenConfigN) []DataPointN {
+	rng := rand.New(rand.NewSource(config.Seed))
+	data := make([]DataPointN, config.NumPoints)
+	for i := range data {
+		x := make([]float64, len(config.TrueW))
+		for j := range x {
+			x[j] = config.XMin + rng.Float64()*(config.XMax-config.XMin)
+			if j < len(config.FeatureScales) {
+				x[j] *= config.FeatureScales[j]
+			}
+		}
+		noise := (rng.Float64()*2 - 1) * config.NoiseLevel
+		data[i] = DataPointN{X: x, YTrue: ForwardN(config.TrueW, config.TrueB, x) + noise}
+	}
+	return data
+}
+
+// ValidateDataGenConfigN checks that an N-feature data config describes at least one feature
+func ValidateDataGenConfigN(config DataGenConfigN) error {
+	if len(config.TrueW) == 0 {
+		return fmt.Errorf("true_w must have one weight per feature")
+	}
+	if len(config.FeatureScales) > len(config.TrueW) {
+		return fmt.Errorf("feature_scales has %d entries for %d features", len(config.FeatureScales), len(config.TrueW))
+	}
+	return nil
+}
+
+// ValidateDatasetN checks if an N-feature dataset is valid for training
+func ValidateDatasetN(data []DataPointN) error {
+	if len(data) == 0 {
+		return fmt.Errorf("dataset is empty")
+	}
+	numFeatures := len(data[0].X)
+	if numFeatures == 0 {
+		return fmt.Errorf("point 0 has no features")
+	}
+	for i, point := range data {
+		if len(point.X) != numFeatures {
+			return fmt.Errorf("point %d has %d features, point 0 has %d", i, len(point.X), numFeatures)
+		}
+		for j, x := range point.X {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return fmt.Errorf("point %d has invalid x[%d] value: %f", i, j, x)
+			}
+		}
+		if math.IsNaN(point.YTrue) || math.IsInf(point.YTrue, 0) {
+			return fmt.Errorf("point %d has invalid YTrue value: %f", i, point.YTrue)
+		}
+	}
+	return nil
+}
+
+// RunTrainingN performs gradient descent on N-feature data and returns snapshots
+func RunTrainingN(data []DataPointN, config TrainingConfigN) ([]SnapshotN, error) {
+	return RunTrainingNContext(context.Background(), data, config)
+}
+
+// RunTrainingNContext trains like RunTrainingN but stops early with ctx.Err() once ctx is done
+func RunTrainingNContext(ctx context.Context, data []DataPointN, config TrainingConfigN) ([]SnapshotN, error) {
+	if err := ValidateDatasetN(data); err != nil {
+		return nil, err
+	}
+	numFeatures := len(data[0].X)
+	w := make([]float64, numFeatures)
+	if config.WInit != nil {
+		if len(config.WInit) != numFeatures {
+			return nil, fmt.Errorf("w_init has %d weights for %d features", len(config.WInit), numFeatures)
+		}
+		copy(w, config.WInit)
+	}
+	b := config.BInit
+
+	snapshots := make([]SnapshotN, 0, config.MaxSteps)
+	for step := 0; step < config.MaxSteps; step++ {
+		// Stop computing once the caller has given up
+		if err := ctx.Err(); err != nil {
+			return snapshots, err
+		}
+
+		loss, gradW, gradB := GradientN(data, w, b)
+		if !config.Bias {
+			gradB = 0
+		}
+		snapshots = append(snapshots, SnapshotN{
+			Step:              step,
+			W:                 append([]float64(nil), w...),
+			B:                 b,
+			GradW:             gradW,
+			GradB:             gradB,
+			Loss:              loss,
+			GradientMagnitude: norm(paramVector(gradW, gradB, config.Bias)),
+		})
+
+		// Update parameters
+		for j := range w {
+			w[j] -= config.LR * gradW[j]
+		}
+		b -= config.LR * gradB
+	}
+	return snapshots, nil
+}
@@ -1,5 +1,7 @@
 package linear
 
+import "github.com/iOliverNguyen/ml-viz/go/linesearch"
+
 // DataPoint2D represents a training example with two input features
 type DataPoint2D struct {
 	X1    float64 `json:"x1"`
@@ -33,14 +35,43 @@ type UpdateDetails2D struct {
 
 // LinearSnapshot captures complete state at one training step
 type LinearSnapshot struct {
-	Step              int                 `json:"step"`
-	W1                float64             `json:"w1"`
-	W2                float64             `json:"w2"`
-	GradW1            float64             `json:"grad_w1"`
-	GradW2            float64             `json:"grad_w2"`
-	Loss              float64             `json:"loss"`
-	GradientMagnitude float64             `json:"gradient_magnitude"`
-	GradientDirection float64             `json:"gradient_direction"`
-	PointDetails      []PointSnapshot2D   `json:"point_details"`
-	UpdateComponents  UpdateDetails2D     `json:"update_components"`
+	Step              int               `json:"step"`
+	W1                float64           `json:"w1"`
+	W2                float64           `json:"w2"`
+	GradW1            float64           `json:"grad_w1"`
+	GradW2            float64           `json:"grad_w2"`
+	Loss              float64           `json:"loss"`
+	GradientMagnitude float64           `json:"gradient_magnitude"`
+	GradientDirection float64           `json:"gradient_direction"`
+	PointDetails      []PointSnapshot2D `json:"point_details"`
+	UpdateComponents  UpdateDetails2D   `json:"update_components"`
+
+	// Curvature: the Hessian (Gauss-Newton matrix for gauss-newton), the Newton
+	// direction -H⁻¹∇L (zero when H is singular), and the BFGS inverse-Hessian estimate
+	Hessian         [2][2]float64  `json:"hessian"`
+	NewtonDirection [2]float64     `json:"newton_direction"`
+	InverseHessian  *[2][2]float64 `json:"inverse_hessian,omitempty"` // bfgs only
+
+	// The update is StepLength·SearchDirection (lr and -∇L for gradient descent)
+	SearchDirection [2]float64 `json:"search_direction"`
+	StepLength      float64    `json:"step_length"`
+	ConjugacyBeta   float64    `json:"conjugacy_beta"`       // β of cg; 0 on restarts
+	Coordinate      int        `json:"coordinate,omitempty"` // coordinate only: 1 when w1 moved, 2 when w2 moved
+
+	// Trial steps of the line search; omitted without one
+	LineSearch *linesearch.Result `json:"line_search,omitempty"`
+
+	// Points of the mini-batch behind the gradient; omitted for full batches
+	Batch []int `json:"batch,omitempty"`
+
+	// Trajectory metrics, filled by AnnotateTrajectory (angles in radians)
+	GradientTurnAngle float64 `json:"gradient_turn_angle"` // angle between this and the previous gradient
+	StepOptimumAngle  float64 `json:"step_optimum_angle"`  // angle between the update and the direction to the optimum
+	PathEfficiency    float64 `json:"path_efficiency"`     // straight-line distance from the start over path length so far
+	SignFlipsW1       int     `json:"sign_flips_w1"`       // times grad_w1 has changed sign so far
+	SignFlipsW2       int     `json:"sign_flips_w2"`       // times grad_w2 has changed sign so far
+
+	// Parameter error w - w* along the Hessian's eigenvectors, filled by AnnotateEigenErrors
+	ErrorEigen1 float64 `json:"error_eigen1"` // along the steepest direction
+	ErrorEigen2 float64 `json:"error_eigen2"` // along the flattest direction
 }
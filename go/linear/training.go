@@ -1,26 +1,60 @@
 package linear
 
+import (
+	"context"
+
+	"github.com/iOliverNguyen/ml-viz/go/linesearch"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+)
+
 // TrainingConfig2D holds configuration for 2-parameter training
 type TrainingConfig2D struct {
 	W1Init   float64 `json:"w1_init"`
 	W2Init   float64 `json:"w2_init"`
 	LR       float64 `json:"lr"`
 	MaxSteps int     `json:"max_steps"`
+
+	Optimizer string  `json:"optimizer,omitempty"` // see the Optimizer constants (default gd)
+	Damping   float64 `json:"damping,omitempty"`   // μ of damped-newton
+
+	// LineSearch picks each step length along the optimizer's direction,
+	// starting from the optimizer's own step; nil keeps that step
+	LineSearch *linesearch.Config `json:"line_search,omitempty"`
+
+	// BatchSize > 0 averages each step's gradient over a shuffled mini-batch
+	// of that many points (gd only); 0 uses the whole dataset
+	BatchSize   int   `json:"batch_size,omitempty"`
+	ShuffleSeed int64 `json:"shuffle_seed,omitempty"` // seeds the batch order
 }
 
 // RunTraining performs gradient descent training and returns snapshots
 func RunTraining(data []DataPoint2D, config TrainingConfig2D) []LinearSnapshot {
+	snapshots, _ := RunTrainingContext(context.Background(), data, config)
+	return snapshots
+}
+
+// RunTrainingContext trains like RunTraining but stops early with ctx.Err() once ctx is done
+func RunTrainingContext(ctx context.Context, data []DataPoint2D, config TrainingConfig2D) ([]LinearSnapshot, error) {
 	w1, w2 := config.W1Init, config.W2Init
 	lr := config.LR
 	steps := config.MaxSteps
 
 	snapshots := make([]LinearSnapshot, 0, steps)
+	opt := newOptimizer2D(config)
+	hessian := AnalyzeHessian(data, 0).Hessian
+	sampler := minibatch.New(len(data), config.BatchSize, config.ShuffleSeed)
 
 	for step := 0; step < steps; step++ {
+		// Stop computing once the caller has given up
+		if err := ctx.Err(); err != nil {
+			return snapshots, err
+		}
+
 		totalLoss := 0.0
 		totalGradW1 := 0.0
 		totalGradW2 := 0.0
 		pointDetails := make([]PointSnapshot2D, 0, len(data))
+		var gaussNewton [2][2]float64 // Σ JᵢJᵢᵀ, Jᵢ = ∂(y_pred - y_true)/∂w = (x1, x2)
 
 		// Compute per-point values
 		for _, point := range data {
@@ -42,6 +76,9 @@ func RunTraining(data []DataPoint2D, config TrainingConfig2D) []LinearSnapshot {
 			totalLoss += pointLoss
 			totalGradW1 += gradW1
 			totalGradW2 += gradW2
+			gaussNewton[0][0] += point.X1 * point.X1
+			gaussNewton[0][1] += point.X1 * point.X2
+			gaussNewton[1][1] += point.X2 * point.X2
 		}
 
 		// Average over dataset
@@ -49,15 +86,43 @@ func RunTraining(data []DataPoint2D, config TrainingConfig2D) []LinearSnapshot {
 		avgLoss := totalLoss / n
 		avgGradW1 := totalGradW1 / n
 		avgGradW2 := totalGradW2 / n
+		for i := range gaussNewton {
+			for j := range gaussNewton[i] {
+				gaussNewton[i][j] *= 2 / n
+			}
+		}
+		gaussNewton[1][0] = gaussNewton[0][1]
+
+		// Stochastic gradient: the loss stays the full-data loss
+		batch := sampler.Next()
+		if batch != nil {
+			avgGradW1, avgGradW2 = 0, 0
+			for _, i := range batch {
+				avgGradW1 += pointDetails[i].GradW1 / float64(len(batch))
+				avgGradW2 += pointDetails[i].GradW2 / float64(len(batch))
+			}
+		}
 
 		gradMag := GradientMagnitude(avgGradW1, avgGradW2)
 		gradDir := GradientDirection(avgGradW1, avgGradW2)
 
 		// Compute updates
-		deltaW1 := -lr * avgGradW1
-		deltaW2 := -lr * avgGradW2
+		grad := [2]float64{avgGradW1, avgGradW2}
+		update := opt.step([2]float64{w1, w2}, grad, hessian, gaussNewton)
+		var search *linesearch.Result
+		if config.LineSearch.Active() {
+			result := searchLine(data, config.LineSearch, [2]float64{w1, w2}, avgLoss, grad, hessian, update)
+			update.StepLength, search = result.Step, &result
+		}
+		delta := update.Delta()
+		deltaW1, deltaW2 := delta[0], delta[1]
 		w1New := w1 + deltaW1
 		w2New := w2 + deltaW2
+		curvature := hessian
+		if config.Optimizer == OptimizerGaussNewton {
+			curvature = gaussNewton
+		}
+		newton, _ := NewtonDirection(curvature, grad)
 
 		// Create snapshot
 		snapshots = append(snapshots, LinearSnapshot{
@@ -70,6 +135,15 @@ func RunTraining(data []DataPoint2D, config TrainingConfig2D) []LinearSnapshot {
 			GradientMagnitude: gradMag,
 			GradientDirection: gradDir,
 			PointDetails:      pointDetails,
+			Hessian:           curvature,
+			NewtonDirection:   newton,
+			InverseHessian:    update.Inverse,
+			SearchDirection:   update.Direction,
+			StepLength:        update.StepLength,
+			ConjugacyBeta:     update.Beta,
+			Coordinate:        update.Coordinate,
+			LineSearch:        search,
+			Batch:             batch,
 			UpdateComponents: UpdateDetails2D{
 				W1Old:   w1,
 				W2Old:   w2,
@@ -87,5 +161,7 @@ func RunTraining(data []DataPoint2D, config TrainingConfig2D) []LinearSnapshot {
 		w1, w2 = w1New, w2New
 	}
 
-	return snapshots
+	AnnotateTrajectory(snapshots, data)
+	AnnotateEigenErrors(snapshots, data)
+	return snapshots, nil
 }
@@ -0,0 +1,134 @@
+package linear
+
+import "math"
+
+// TrajectoryMetrics summarizes how directly a run travels to the optimum.
+// Angles are in radians.
+type TrajectoryMetrics struct {
+	HasOptimum            bool    `json:"has_optimum"` // false when the features are collinear
+	OptimumW1             float64 `json:"optimum_w1"`
+	OptimumW2             float64 `json:"optimum_w2"`
+	PathLength            float64 `json:"path_length"`              // total distance traveled in parameter space
+	PathEfficiency        float64 `json:"path_efficiency"`          // straight-line distance over path length (1 = straight)
+	MeanGradientTurnAngle float64 `json:"mean_gradient_turn_angle"` // mean angle between consecutive gradients
+	MaxGradientTurnAngle  float64 `json:"max_gradient_turn_angle"`  // largest angle between consecutive gradients
+	MeanStepOptimumAngle  float64 `json:"mean_step_optimum_angle"`  // mean angle between the update and the direction to the optimum
+	ZigzagFraction        float64 `json:"zigzag_fraction"`          // fraction of steps whose gradient turns by more than 90°
+	SignFlipsW1           int     `json:"sign_flips_w1"`            // times grad_w1 changed sign
+	SignFlipsW2           int     `json:"sign_flips_w2"`            // times grad_w2 changed sign
+}
+
+// Optimum returns the least-squares solution of y = w1*x1 + w2*x2 from the
+// normal equations. ok is false when the features are (nearly) collinear.
+func Optimum(data []DataPoint2D) (w1, w2 float64, ok bool) {
+	var s11, s12, s22, s1y, s2y float64
+	for _, p := range data {
+		s11 += p.X1 * p.X1
+		s12 += p.X1 * p.X2
+		s22 += p.X2 * p.X2
+		s1y += p.X1 * p.YTrue
+		s2y += p.X2 * p.YTrue
+	}
+	det := s11*s22 - s12*s12
+	if math.Abs(det) <= 1e-12*math.Max(s11*s22, 1e-300) {
+		return 0, 0, false
+	}
+	return (s22*s1y - s12*s2y) / det, (s11*s2y - s12*s1y) / det, true
+}
+
+// AnnotateTrajectory fills the trajectory metrics of each snapshot
+func AnnotateTrajectory(snapshots []LinearSnapshot, data []DataPoint2D) {
+	optW1, optW2, hasOptimum := Optimum(data)
+	pathLength := 0.0
+	flipsW1, flipsW2 := 0, 0
+
+	for i := range snapshots {
+		s := &snapshots[i]
+		if i > 0 {
+			prev := &snapshots[i-1]
+			s.GradientTurnAngle = angleBetween(prev.GradW1, prev.GradW2, s.GradW1, s.GradW2)
+			pathLength += math.Hypot(s.W1-prev.W1, s.W2-prev.W2)
+			if signFlip(prev.GradW1, s.GradW1) {
+				flipsW1++
+			}
+			if signFlip(prev.GradW2, s.GradW2) {
+				flipsW2++
+			}
+		}
+		// The real update: only plain gradient descent moves along -∇L. At
+		// the optimum the direction to it is rounding noise, so the angle is 0.
+		if hasOptimum && !atOptimum(s.W1, s.W2, optW1, optW2) {
+			s.StepOptimumAngle = angleBetween(s.UpdateComponents.DeltaW1, s.UpdateComponents.DeltaW2, optW1-s.W1, optW2-s.W2)
+		}
+		s.PathEfficiency = 1
+		if pathLength > 0 {
+			s.PathEfficiency = finite(math.Hypot(s.W1-snapshots[0].W1, s.W2-snapshots[0].W2) / pathLength)
+		}
+		s.SignFlipsW1 = flipsW1
+		s.SignFlipsW2 = flipsW2
+	}
+}
+
+// SummarizeTrajectory aggregates the trajectory metrics of annotated snapshots
+func SummarizeTrajectory(snapshots []LinearSnapshot, data []DataPoint2D) TrajectoryMetrics {
+	var m TrajectoryMetrics
+	m.OptimumW1, m.OptimumW2, m.HasOptimum = Optimum(data)
+	if len(snapshots) == 0 {
+		return m
+	}
+
+	last := snapshots[len(snapshots)-1]
+	m.PathEfficiency = last.PathEfficiency
+	m.SignFlipsW1 = last.SignFlipsW1
+	m.SignFlipsW2 = last.SignFlipsW2
+
+	var turnSum, optimumSum float64
+	zigzags := 0
+	for i, s := range snapshots {
+		optimumSum += s.StepOptimumAngle
+		if i == 0 {
+			continue
+		}
+		m.PathLength += math.Hypot(s.W1-snapshots[i-1].W1, s.W2-snapshots[i-1].W2)
+		turnSum += s.GradientTurnAngle
+		m.MaxGradientTurnAngle = math.Max(m.MaxGradientTurnAngle, s.GradientTurnAngle)
+		if s.GradientTurnAngle > math.Pi/2 {
+			zigzags++
+		}
+	}
+	m.PathLength = finite(m.PathLength)
+	m.MeanStepOptimumAngle = optimumSum / float64(len(snapshots))
+	if turns := len(snapshots) - 1; turns > 0 {
+		m.MeanGradientTurnAngle = turnSum / float64(turns)
+		m.ZigzagFraction = float64(zigzags) / float64(turns)
+	}
+	return m
+}
+
+// angleBetween returns the angle between two vectors in [0, π], or 0 if either is zero or not finite
+func angleBetween(ax, ay, bx, by float64) float64 {
+	na, nb := math.Hypot(ax, ay), math.Hypot(bx, by)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	cos := (ax*bx + ay*by) / (na * nb)
+	return finite(math.Acos(math.Max(-1, math.Min(1, cos))))
+}
+
+// atOptimum reports whether (w1, w2) is the optimum up to rounding
+func atOptimum(w1, w2, optW1, optW2 float64) bool {
+	return math.Hypot(optW1-w1, optW2-w2) <= 1e-9*math.Max(1, math.Hypot(optW1, optW2))
+}
+
+// signFlip reports whether a gradient component changed sign (zero is not a sign)
+func signFlip(prev, cur float64) bool {
+	return (prev > 0 && cur < 0) || (prev < 0 && cur > 0)
+}
+
+// finite returns v, or 0 for NaN and ±Inf, which JSON cannot encode
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,200 @@
+// Package linesearch picks the step length of a training update along a search
+// direction instead of using the learning rate as is. The trainers of every
+// phase share it; each snapshot records the trial steps and the checks they
+// passed, so a run shows how the step was chosen.
+package linesearch
+
+import (
+	"fmt"
+	"math"
+)
+
+// Line search methods of Config.Method
+const (
+	MethodFixed  = "fixed"  // step by the learning rate (the default)
+	MethodArmijo = "armijo" // backtrack from the first trial step until the loss decreases enough
+	MethodWolfe  = "wolfe"  // bracket and bisect until the strong Wolfe conditions hold
+	MethodExact  = "exact"  // minimize along the direction in closed form; quadratic losses only
+)
+
+// Defaults of the Config constants
+const (
+	DefaultC1        = 1e-4
+	DefaultC2        = 0.9
+	DefaultShrink    = 0.5
+	DefaultMaxTrials = 30
+)
+
+// Config chooses a line search. The first trial step is the trainer's own
+// step: the learning rate for gradient descent, 1 for Newton's method.
+type Config struct {
+	Method    string  `json:"method"`
+	C1        float64 `json:"c1,omitempty"`         // sufficient decrease constant (default 1e-4)
+	C2        float64 `json:"c2,omitempty"`         // curvature constant of wolfe (default 0.9)
+	Shrink    float64 `json:"shrink,omitempty"`     // backtracking factor of armijo (default 0.5)
+	MaxTrials int     `json:"max_trials,omitempty"` // default 30
+}
+
+// Active reports whether c searches at all. A nil config steps by the learning rate.
+func (c *Config) Active() bool {
+	return c != nil && c.Method != "" && c.Method != MethodFixed
+}
+
+// Validate checks the method and constants. quadratic tells whether the loss
+// is quadratic in the parameters, which exact line search needs.
+func (c *Config) Validate(quadratic bool) error {
+	if c == nil {
+		return nil
+	}
+	switch c.Method {
+	case "", MethodFixed, MethodArmijo, MethodWolfe:
+	case MethodExact:
+		if !quadratic {
+			return fmt.Errorf("exact line search needs a quadratic loss; use %s or %s", MethodArmijo, MethodWolfe)
+		}
+	default:
+		return fmt.Errorf("unknown line search method %q (want %s, %s, %s or %s)", c.Method,
+			MethodFixed, MethodArmijo, MethodWolfe, MethodExact)
+	}
+	c1, c2 := c.c1(), c.c2()
+	if !(0 < c1 && c1 < c2 && c2 < 1) {
+		return fmt.Errorf("line search constants must satisfy 0 < c1 < c2 < 1, got c1=%g c2=%g", c1, c2)
+	}
+	if shrink := c.shrink(); !(0 < shrink && shrink < 1) {
+		return fmt.Errorf("line search shrink must be in (0, 1), got %g", shrink)
+	}
+	if c.MaxTrials < 0 {
+		return fmt.Errorf("line search max_trials must be non-negative")
+	}
+	return nil
+}
+
+func (c *Config) c1() float64 { return orDefault(c.C1, DefaultC1) }
+
+func (c *Config) c2() float64 { return orDefault(c.C2, DefaultC2) }
+
+func (c *Config) shrink() float64 { return orDefault(c.Shrink, DefaultShrink) }
+
+func (c *Config) maxTrials() int {
+	if c.MaxTrials == 0 {
+		return DefaultMaxTrials
+	}
+	return c.MaxTrials
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// Line is the loss along a search direction d from parameters θ
+type Line struct {
+	Loss      float64 // L(θ)
+	Slope     float64 // ∇L(θ)·d, negative along a descent direction
+	Curvature float64 // dᵀHd, for exact line search on a quadratic loss
+
+	// Eval returns L(θ + αd) and ∇L(θ + αd)·d
+	Eval func(step float64) (loss, slope float64)
+}
+
+// Trial is one step length tried
+type Trial struct {
+	Step       float64 `json:"step"`       // α
+	Loss       float64 `json:"loss"`       // L(θ + αd)
+	Bound      float64 `json:"bound"`      // L(θ) + c1·α·slope, the largest loss with sufficient decrease
+	Sufficient bool    `json:"sufficient"` // Loss <= Bound (the Armijo condition)
+
+	// The curvature check of wolfe: the slope at the trial and whether |slope| <= c2·|∇L(θ)·d|
+	Slope     *float64 `json:"slope,omitempty"`
+	Curvature *bool    `json:"curvature,omitempty"`
+}
+
+// Result is the outcome of a line search
+type Result struct {
+	Method    string  `json:"method"`
+	Loss      float64 `json:"loss"`  // L(θ)
+	Slope     float64 `json:"slope"` // ∇L(θ)·d
+	Trials    []Trial `json:"trials"`
+	Step      float64 `json:"step"`      // the accepted α
+	Satisfied bool    `json:"satisfied"` // false when the trials ran out or d is not a descent direction
+}
+
+// Search finds a step length along line, starting from initial
+func Search(c *Config, line Line, initial float64) Result {
+	r := Result{Method: c.Method, Loss: line.Loss, Slope: line.Slope, Trials: []Trial{}}
+	if !(line.Slope < 0) {
+		// Already at a stationary point (slope 0) or not a descent direction
+		r.Satisfied = line.Slope == 0
+		return r
+	}
+	switch c.Method {
+	case MethodExact:
+		c.exact(&r, line, initial)
+	case MethodWolfe:
+		c.wolfe(&r, line, initial)
+	default:
+		c.armijo(&r, line, initial)
+	}
+	return r
+}
+
+// try evaluates a trial step and records it
+func (c *Config) try(r *Result, line Line, step float64, wolfe bool) Trial {
+	loss, slope := line.Eval(step)
+	t := Trial{Step: step, Loss: loss, Bound: line.Loss + c.c1()*step*line.Slope}
+	t.Sufficient = loss <= t.Bound
+	if wolfe {
+		curvature := math.Abs(slope) <= c.c2()*math.Abs(line.Slope)
+		t.Slope, t.Curvature = &slope, &curvature
+	}
+	r.Trials = append(r.Trials, t)
+	return t
+}
+
+// armijo halves (by Shrink) the step until the loss decreases enough
+func (c *Config) armijo(r *Result, line Line, step float64) {
+	for i := 0; i < c.maxTrials(); i++ {
+		if c.try(r, line, step, false).Sufficient {
+			r.Step, r.Satisfied = step, true
+			return
+		}
+		step *= c.shrink()
+	}
+}
+
+// wolfe brackets a step satisfying the strong Wolfe conditions, doubling while
+// the slope is still steeply downhill and bisecting once a trial overshoots
+func (c *Config) wolfe(r *Result, line Line, step float64) {
+	lo, hi := 0.0, math.Inf(1)
+	for i := 0; i < c.maxTrials(); i++ {
+		t := c.try(r, line, step, true)
+		switch {
+		case !t.Sufficient || *t.Slope > 0 && !*t.Curvature:
+			hi = step // too far: the loss rose or the slope turned steeply uphill
+		case !*t.Curvature:
+			lo = step // too short: still steeply downhill
+		default:
+			r.Step, r.Satisfied = step, true
+			return
+		}
+		if math.IsInf(hi, 1) {
+			step *= 2
+		} else {
+			step = (lo + hi) / 2
+		}
+	}
+	r.Step = lo // the longest step with sufficient decrease, if any
+}
+
+// exact steps to the minimum of the quadratic along the line, -slope/curvature.
+// Without positive curvature it falls back to the initial step.
+func (c *Config) exact(r *Result, line Line, initial float64) {
+	step := initial
+	if line.Curvature > 0 {
+		step = -line.Slope / line.Curvature
+	}
+	c.try(r, line, step, false)
+	r.Step, r.Satisfied = step, line.Curvature > 0
+}
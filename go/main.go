@@ -1,7 +1,11 @@
 package core
 
 import (
+	"context"
 	"fmt"
+
+	"github.com/iOliverNguyen/ml-viz/go/linesearch"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
 )
 
 // TrainingConfig holds training hyperparameters
@@ -9,6 +13,14 @@ type TrainingConfig struct {
 	WInit float64 `json:"w_init"`
 	LR    float64 `json:"lr"`
 	Steps int     `json:"steps"`
+
+	// LineSearch picks each step length, starting from LR; nil steps by LR
+	LineSearch *linesearch.Config `json:"line_search,omitempty"`
+
+	// BatchSize > 0 averages each step's gradient over a shuffled mini-batch
+	// of that many points; 0 uses the whole dataset
+	BatchSize   int   `json:"batch_size,omitempty"`
+	ShuffleSeed int64 `json:"shuffle_seed,omitempty"` // seeds the batch order
 }
 
 // DefaultTrainingConfig returns default training parameters
@@ -22,6 +34,13 @@ func DefaultTrainingConfig() TrainingConfig {
 
 // RunTraining trains a linear model on the given dataset
 func RunTrainingWithDataset(data []DataPoint, config TrainingConfig) []Snapshot {
+	snapshots, _ := RunTrainingWithDatasetContext(context.Background(), data, config)
+	return snapshots
+}
+
+// RunTrainingWithDatasetContext trains like RunTrainingWithDataset but stops
+// early with ctx.Err() once ctx is done
+func RunTrainingWithDatasetContext(ctx context.Context, data []DataPoint, config TrainingConfig) ([]Snapshot, error) {
 	// Training hyperparameters
 	w := config.WInit
 	lr := config.LR
@@ -29,6 +48,7 @@ func RunTrainingWithDataset(data []DataPoint, config TrainingConfig) []Snapshot
 
 	// Storage for all training snapshots
 	snapshots := []Snapshot{}
+	sampler := minibatch.New(len(data), config.BatchSize, config.ShuffleSeed)
 
 	fmt.Println("Starting training...")
 	fmt.Printf("Initial w: %.4f\n", w)
@@ -38,6 +58,11 @@ func RunTrainingWithDataset(data []DataPoint, config TrainingConfig) []Snapshot
 
 	// Training loop - explicit and imperative (no Model or Trainer abstraction)
 	for step := 0; step < steps; step++ {
+		// Stop computing once the caller has given up
+		if err := ctx.Err(); err != nil {
+			return snapshots, err
+		}
+
 		totalLoss := 0.0
 		totalGrad := 0.0
 		pointDetails := make([]PointSnapshot, 0, len(data))
@@ -70,8 +95,25 @@ func RunTrainingWithDataset(data []DataPoint, config TrainingConfig) []Snapshot
 		avgLoss := totalLoss / float64(len(data))
 		avgGrad := totalGrad / float64(len(data))
 
+		// Stochastic gradient: the loss stays the full-data loss
+		batch := sampler.Next()
+		if batch != nil {
+			avgGrad = 0.0
+			for _, i := range batch {
+				avgGrad += pointDetails[i].PointGrad / float64(len(batch))
+			}
+		}
+
+		// Choose the step length along -grad_w
+		stepLR := lr
+		var search *linesearch.Result
+		if config.LineSearch.Active() {
+			result := searchLine(data, config.LineSearch, w, avgLoss, avgGrad, lr)
+			stepLR, search = result.Step, &result
+		}
+
 		// Compute w_new before creating snapshot
-		deltaW := -lr * avgGrad
+		deltaW := -stepLR * avgGrad
 		wNew := w + deltaW
 
 		// Create snapshot BEFORE parameter update
@@ -82,9 +124,11 @@ func RunTrainingWithDataset(data []DataPoint, config TrainingConfig) []Snapshot
 			GradW: avgGrad,
 			Loss:  avgLoss,
 			PointDetails: pointDetails,
+			LineSearch:   search,
+			Batch:        batch,
 			UpdateComponents: UpdateDetails{
 				WOld:   w,
-				LR:     lr,
+				LR:     stepLR,
 				GradW:  avgGrad,
 				DeltaW: deltaW,
 				WNew:   wNew,
@@ -104,7 +148,30 @@ func RunTrainingWithDataset(data []DataPoint, config TrainingConfig) []Snapshot
 	fmt.Println()
 	fmt.Printf("Training complete! Final w: %.4f\n", w)
 
-	return snapshots
+	return snapshots, nil
+}
+
+// searchLine runs a line search along -grad from w. The loss is quadratic in w
+// with curvature 2·mean(x²), so exact line search applies.
+func searchLine(data []DataPoint, config *linesearch.Config, w, loss, grad, lr float64) linesearch.Result {
+	d := -grad
+	curvature := 0.0
+	for _, point := range data {
+		curvature += 2 * point.X * point.X / float64(len(data))
+	}
+	return linesearch.Search(config, linesearch.Line{
+		Loss:      loss,
+		Slope:     grad * d,
+		Curvature: d * curvature * d,
+		Eval: func(step float64) (float64, float64) {
+			trialLoss, trialGrad := 0.0, 0.0
+			for _, point := range data {
+				trialLoss += Loss(Forward(w+step*d, point.X), point.YTrue) / float64(len(data))
+				trialGrad += GradW(w+step*d, point.X, point.YTrue) / float64(len(data))
+			}
+			return trialLoss, trialGrad * d
+		},
+	}, lr)
 }
 
 // RunTraining runs training with default dataset and config (for backward compatibility)
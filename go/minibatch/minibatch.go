@@ -0,0 +1,60 @@
+// Package minibatch deals the points of a dataset into shuffled mini-batches
+// for stochastic gradient descent. The trainers of every phase share it, so a
+// shuffle seed replays the same batch order in each phase; each snapshot
+// records the points of its batch.
+package minibatch
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Sampler deals batches of a fixed size, reshuffling the points every epoch.
+// The points left over at the end of an epoch are dropped, so every batch has
+// the same size and the same gradient noise.
+type Sampler struct {
+	size  int
+	rng   *rand.Rand
+	order []int
+	next  int
+}
+
+// New returns a sampler of batches of size points out of n. A size of 0, or of
+// at least n, is full-batch: Next always returns nil.
+func New(n, size int, seed int64) *Sampler {
+	if size <= 0 || size >= n {
+		return &Sampler{}
+	}
+	s := &Sampler{size: size, rng: rand.New(rand.NewSource(seed)), order: make([]int, n)}
+	for i := range s.order {
+		s.order[i] = i
+	}
+	s.next = n // shuffle before the first batch
+	return s
+}
+
+// Next returns the point indices of the next batch, or nil when every step uses all points
+func (s *Sampler) Next() []int {
+	if s.size == 0 {
+		return nil
+	}
+	if s.next+s.size > len(s.order) {
+		s.rng.Shuffle(len(s.order), func(i, j int) { s.order[i], s.order[j] = s.order[j], s.order[i] })
+		s.next = 0
+	}
+	batch := append([]int(nil), s.order[s.next:s.next+s.size]...)
+	s.next += s.size
+	return batch
+}
+
+// Validate checks a batch size. searching tells whether the run uses a line
+// search, which compares losses of the full dataset and so needs full batches.
+func Validate(size int, searching bool) error {
+	if size < 0 {
+		return fmt.Errorf("batch_size must be non-negative, got %d", size)
+	}
+	if size > 0 && searching {
+		return fmt.Errorf("line search needs full batches (batch_size 0)")
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package neuron
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // Sigmoid computes the sigmoid activation function: σ(z) = 1 / (1 + e^(-z))
 func Sigmoid(z float64) float64 {
@@ -37,36 +40,247 @@ func TanhDerivative(z float64) float64 {
 	return 1.0 - t*t;
 }
 
+// LeakyReLU computes the leaky ReLU activation function: z if z > 0, else αz
+func LeakyReLU(z, alpha float64) float64 {
+	if z > 0 {
+		return z;
+	}
+	return alpha * z;
+}
+
+// LeakyReLUDerivative computes the derivative of leaky ReLU: 1 if z > 0, else α
+func LeakyReLUDerivative(z, alpha float64) float64 {
+	if z > 0 {
+		return 1.0;
+	}
+	return alpha;
+}
+
+// ELU computes the ELU activation function: z if z > 0, else α(e^z - 1)
+func ELU(z, alpha float64) float64 {
+	if z > 0 {
+		return z;
+	}
+	return alpha * math.Expm1(z);
+}
+
+// ELUDerivative computes the derivative of ELU: 1 if z > 0, else αe^z
+func ELUDerivative(z, alpha float64) float64 {
+	if z > 0 {
+		return 1.0;
+	}
+	return alpha * math.Exp(z);
+}
+
+// GELU computes the exact GELU activation function: z·Φ(z), with Φ the standard normal CDF
+func GELU(z float64) float64 {
+	return z * normalCDF(z);
+}
+
+// GELUDerivative computes the derivative of GELU: Φ(z) + z·φ(z)
+func GELUDerivative(z float64) float64 {
+	return normalCDF(z) + z*math.Exp(-z*z/2)/math.Sqrt(2*math.Pi);
+}
+
+// normalCDF computes the standard normal CDF: Φ(z) = (1 + erf(z/√2)) / 2
+func normalCDF(z float64) float64 {
+	return 0.5 * (1.0 + math.Erf(z/math.Sqrt2));
+}
+
+// Softplus computes the softplus activation function: ln(1 + e^z), without overflow for large z
+func Softplus(z float64) float64 {
+	if z > 0 {
+		return z + math.Log1p(math.Exp(-z));
+	}
+	return math.Log1p(math.Exp(z));
+}
+
+// SoftplusDerivative computes the derivative of softplus: σ(z)
+func SoftplusDerivative(z float64) float64 {
+	return Sigmoid(z);
+}
+
+// Swish computes the swish (SiLU) activation function: z·σ(z)
+func Swish(z float64) float64 {
+	return z * Sigmoid(z);
+}
+
+// SwishDerivative computes the derivative of swish: σ(z) + z·σ(z)(1 - σ(z))
+func SwishDerivative(z float64) float64 {
+	s := Sigmoid(z);
+	return s + z*s*(1.0-s);
+}
+
+// HardSigmoid computes the hard sigmoid activation function: clip(z/6 + 1/2, 0, 1)
+func HardSigmoid(z float64) float64 {
+	return math.Max(0, math.Min(1, z/6+0.5));
+}
+
+// HardSigmoidDerivative computes the derivative of hard sigmoid: 1/6 if -3 < z < 3, else 0
+func HardSigmoidDerivative(z float64) float64 {
+	if z > -3 && z < 3 {
+		return 1.0 / 6;
+	}
+	return 0.0;
+}
+
 // IsSaturated checks if the activation function is in saturation zone
 // Threshold: |σ'(z)| < 0.01 means gradient is less than 1% of maximum
 func IsSaturated(sigmaPrime float64) bool {
 	return math.Abs(sigmaPrime) < 0.01;
 }
 
-// ApplyActivation applies the specified activation function to z
+// Activations lists the supported activation function names
+var Activations = []string{"sigmoid", "relu", "tanh", "leaky_relu", "elu", "gelu", "softplus", "swish", "linear", "hard_sigmoid"};
+
+// Default values of the activation parameter α
+const (
+	DefaultLeakyReLUSlope = 0.01
+	DefaultELUAlpha       = 1.0
+)
+
+// IsValidActivation reports whether the activation function name is supported
+func IsValidActivation(activation string) bool {
+	for _, name := range Activations {
+		if name == activation {
+			return true;
+		}
+	}
+	return false;
+}
+
+// ApplyActivation applies the specified activation function to z, with the default α
 func ApplyActivation(z float64, activation string) float64 {
-	switch activation {
+	return NewActivationFunc(activation, 0).Apply(z);
+}
+
+// ApplyActivationDerivative applies the derivative of the specified activation function, with the default α
+func ApplyActivationDerivative(z float64, activation string) float64 {
+	return NewActivationFunc(activation, 0).Derivative(z);
+}
+
+// hasAlpha reports whether the activation function takes the parameter α
+func hasAlpha(activation string) bool {
+	return activation == "leaky_relu" || activation == "elu";
+}
+
+// ActivationFunc is an activation function with its parameter resolved
+type ActivationFunc struct {
+	Name  string
+	Alpha float64 // leaky_relu negative slope or elu scale; 0 for the others
+}
+
+// NewActivationFunc returns the named activation function, with α defaulted when 0
+func NewActivationFunc(name string, alpha float64) ActivationFunc {
+	if alpha == 0 {
+		switch name {
+		case "leaky_relu":
+			alpha = DefaultLeakyReLUSlope;
+		case "elu":
+			alpha = DefaultELUAlpha;
+		}
+	}
+	return ActivationFunc{Name: name, Alpha: alpha};
+}
+
+// ActivationFunc returns the activation function of the training config
+func (c TrainingConfig) ActivationFunc() ActivationFunc {
+	return NewActivationFunc(c.Activation, c.Alpha);
+}
+
+// ValidateActivation checks the activation name and its α of a training config
+func ValidateActivation(config TrainingConfig) error {
+	if !IsValidActivation(config.Activation) {
+		return fmt.Errorf("invalid activation %q, expected one of %v", config.Activation, Activations);
+	}
+	if config.Alpha == 0 {
+		return nil;
+	}
+	if !hasAlpha(config.Activation) {
+		return fmt.Errorf("alpha only applies to leaky_relu and elu, not %s", config.Activation);
+	}
+	if config.Alpha < 0 {
+		return fmt.Errorf("alpha of %s must be positive, got %g", config.Activation, config.Alpha);
+	}
+	if config.Activation == "leaky_relu" && config.Alpha >= 1 {
+		return fmt.Errorf("alpha of leaky_relu must be below 1, got %g", config.Alpha);
+	}
+	return nil;
+}
+
+// Apply applies the activation function to z
+func (f ActivationFunc) Apply(z float64) float64 {
+	switch f.Name {
 	case "sigmoid":
 		return Sigmoid(z);
 	case "relu":
 		return ReLU(z);
 	case "tanh":
 		return Tanh(z);
+	case "leaky_relu":
+		return LeakyReLU(z, f.Alpha);
+	case "elu":
+		return ELU(z, f.Alpha);
+	case "gelu":
+		return GELU(z);
+	case "softplus":
+		return Softplus(z);
+	case "swish":
+		return Swish(z);
+	case "linear":
+		return z;
+	case "hard_sigmoid":
+		return HardSigmoid(z);
 	default:
 		return Sigmoid(z); // default to sigmoid
 	}
 }
 
-// ApplyActivationDerivative applies the derivative of the specified activation function
-func ApplyActivationDerivative(z float64, activation string) float64 {
-	switch activation {
+// Derivative applies the derivative of the activation function to z
+func (f ActivationFunc) Derivative(z float64) float64 {
+	switch f.Name {
 	case "sigmoid":
 		return SigmoidDerivative(z);
 	case "relu":
 		return ReLUDerivative(z);
 	case "tanh":
 		return TanhDerivative(z);
+	case "leaky_relu":
+		return LeakyReLUDerivative(z, f.Alpha);
+	case "elu":
+		return ELUDerivative(z, f.Alpha);
+	case "gelu":
+		return GELUDerivative(z);
+	case "softplus":
+		return SoftplusDerivative(z);
+	case "swish":
+		return SwishDerivative(z);
+	case "linear":
+		return 1.0;
+	case "hard_sigmoid":
+		return HardSigmoidDerivative(z);
 	default:
 		return SigmoidDerivative(z); // default to sigmoid
 	}
 }
+
+// Saturated reports whether z is in the saturation zone of the activation function:
+//   - sigmoid, tanh, elu, softplus: |σ'(z)| < 0.01 (IsSaturated)
+//   - relu, hard_sigmoid: σ'(z) = 0, the flat pieces where no gradient flows
+//   - gelu, swish: |σ'(z)| < 0.01 in the negative tail only, past the minimum of
+//     σ'; σ' also crosses 0 near z ≈ -0.75 (gelu) and z ≈ -1.28 (swish) without saturating
+//   - leaky_relu, linear: never, the gradient never vanishes
+func (f ActivationFunc) Saturated(z, sigmaPrime float64) bool {
+	switch f.Name {
+	case "relu", "hard_sigmoid":
+		return sigmaPrime == 0;
+	case "gelu":
+		return z < -math.Sqrt2 && IsSaturated(sigmaPrime);
+	case "swish":
+		return z < -2.4 && IsSaturated(sigmaPrime);
+	case "leaky_relu", "linear":
+		return false;
+	default:
+		return IsSaturated(sigmaPrime);
+	}
+}
@@ -0,0 +1,81 @@
+package neuron
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/iOliverNguyen/ml-viz/go/casefile"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+)
+
+// builtinCaseFiles holds the Phase 3 case library, one file per case
+//
+//go:embed cases/*.yaml
+var builtinCaseFiles embed.FS
+
+var caseLibrary = casefile.MustLoadLibrary(builtinCaseFiles, "cases",
+	func(c CaseSpec) string { return c.CaseID },
+	ValidateCase)
+
+// Cases returns the Phase 3 case library: the built-in cases plus those added with AddCaseFiles
+func Cases() []CaseSpec {
+	return caseLibrary.Cases()
+}
+
+// LoadCases decodes and validates Phase 3 case files
+func LoadCases(files []casefile.File) ([]CaseSpec, error) {
+	return caseLibrary.Load(files)
+}
+
+// AddCaseFiles loads the case files of dir into the library. A case with the
+// ID of an existing case replaces it.
+func AddCaseFiles(dir string) error {
+	return caseLibrary.AddDir(dir)
+}
+
+// ValidateCase checks that a case is complete and fills in its activation
+func ValidateCase(c *CaseSpec) error {
+	if err := casefile.ValidateID(c.CaseID); err != nil {
+		return err
+	}
+	if c.Name == "" || c.Category == "" {
+		return fmt.Errorf("case %s: name and category are required", c.CaseID)
+	}
+	if c.Activation != "" && c.Activation != c.Training.Activation {
+		return fmt.Errorf("case %s: activation %q does not match training_config.activation %q", c.CaseID, c.Activation, c.Training.Activation)
+	}
+	if err := ValidateActivation(c.Training); err != nil {
+		return fmt.Errorf("case %s: %w", c.CaseID, err)
+	}
+	c.Activation = c.Training.Activation
+	if _, err := GenerateDataset(c.DataConfig); err != nil {
+		return fmt.Errorf("case %s: data_config: %w", c.CaseID, err)
+	}
+	if len(c.InitParams.W) != len(c.DataConfig.WTrue) {
+		return fmt.Errorf("case %s: init_params.w must have %d weights", c.CaseID, len(c.DataConfig.WTrue))
+	}
+	if err := validateTraining(c.Training); err != nil {
+		return fmt.Errorf("case %s: training_config: %w", c.CaseID, err)
+	}
+	if err := c.Race.Validate(len(c.InitParams.W)); err != nil {
+		return fmt.Errorf("case %s: race: %w", c.CaseID, err)
+	}
+	if err := c.Expect.Validate(); err != nil {
+		return fmt.Errorf("case %s: %w", c.CaseID, err)
+	}
+	if c.Expect != nil && c.Expect.DeadReLUFraction != nil && c.Activation != "relu" {
+		return fmt.Errorf("case %s: dead_relu_fraction only applies to relu cases", c.CaseID)
+	}
+	return nil
+}
+
+// validateTraining checks the training config of a case or race run
+func validateTraining(config TrainingConfig) error {
+	if config.LearningRate <= 0 || config.NumSteps <= 0 {
+		return fmt.Errorf("needs a positive learning_rate and num_steps")
+	}
+	if err := config.LineSearch.Validate(false); err != nil {
+		return err
+	}
+	return minibatch.Validate(config.BatchSize, config.LineSearch.Active())
+}
@@ -1,9 +1,41 @@
 package neuron
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 )
 
+// DataGenConfig configures random linear dataset generation for the neuron
+type DataGenConfig struct {
+	NumPoints   int           `json:"num_points"`
+	WTrue       []float64     `json:"w_true"`  // [w1, w2]
+	BTrue       float64       `json:"b_true"`
+	NoiseStdDev float64       `json:"noise_std_dev"`
+	XRange      [2][2]float64 `json:"x_range"` // [[x1_min, x1_max], [x2_min, x2_max]]
+	Seed        int64         `json:"seed"`
+}
+
+// GenerateDataset generates a linear dataset from a DataGenConfig
+func GenerateDataset(config DataGenConfig) ([]DataPoint2DNeuron, error) {
+	if config.NumPoints <= 0 {
+		return nil, fmt.Errorf("num_points must be positive, got %d", config.NumPoints);
+	}
+	if len(config.WTrue) != 2 {
+		return nil, fmt.Errorf("w_true must have 2 weights, got %d", len(config.WTrue));
+	}
+	if config.NoiseStdDev < 0 {
+		return nil, fmt.Errorf("noise_std_dev must be non-negative, got %f", config.NoiseStdDev);
+	}
+	for i, r := range config.XRange {
+		if r[1] <= r[0] {
+			return nil, fmt.Errorf("x_range[%d] max must be greater than min", i);
+		}
+	}
+
+	return GenerateLinearDataset2D(config.NumPoints, config.WTrue, config.BTrue, config.NoiseStdDev, config.XRange, config.Seed), nil;
+}
+
 // GenerateLinearDataset2D generates a 2D linear dataset: y = w_true·x + b_true + noise
 func GenerateLinearDataset2D(numPoints int, wTrue []float64, bTrue float64, noiseStdDev float64, xRange [2][2]float64, seed int64) []DataPoint2DNeuron {
 	rng := rand.New(rand.NewSource(seed));
@@ -69,3 +101,26 @@ func SimpleLinearTarget(wTrue []float64, bTrue float64) func([]float64) float64
 		return y;
 	};
 }
+
+// ValidateDataset checks that a dataset is non-empty, finite and matches the neuron's feature count
+func ValidateDataset(dataset []DataPoint2DNeuron, numFeatures int) error {
+	if len(dataset) == 0 {
+		return fmt.Errorf("dataset is empty");
+	}
+
+	for i, point := range dataset {
+		if len(point.X) != numFeatures {
+			return fmt.Errorf("point %d has %d features, expected %d", i, len(point.X), numFeatures);
+		}
+		for j, x := range point.X {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return fmt.Errorf("point %d has invalid x%d value: %f", i, j+1, x);
+			}
+		}
+		if math.IsNaN(point.Y) || math.IsInf(point.Y, 0) {
+			return fmt.Errorf("point %d has invalid Y value: %f", i, point.Y);
+		}
+	}
+
+	return nil;
+}
@@ -5,86 +5,163 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/expect"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
 )
 
+// CaseSpec describes one Phase 3 case, declared in a file under cases/.
+// Activation is taken from the training config.
+type CaseSpec struct {
+	CaseID      string         `json:"case_id"`
+	Name        string         `json:"name"`
+	Emoji       string         `json:"emoji"`
+	Description string         `json:"description"`
+	Category    string         `json:"category"`
+	Activation  string         `json:"activation,omitempty"`
+	DataConfig  DataGenConfig  `json:"data_config"`
+	InitParams  NeuronParams   `json:"init_params"`
+	Training    TrainingConfig `json:"training_config"`
+	Insights    []string       `json:"insights,omitempty"`
+
+	// Expect holds the checkable claims of the insights; generation fails when they no longer hold
+	Expect *expect.Expectations `json:"expect,omitempty"`
+
+	// Race trains other runs on the case's dataset and ranks them
+	Race *Race `json:"race,omitempty"`
+}
+
+// CaseManifest lists all Phase 3 cases, like the Phase 1 and Phase 2 manifests
+type CaseManifest struct {
+	Version string         `json:"version"`
+	Cases   []ManifestCase `json:"cases"`
+}
+
+// ManifestCase is the manifest entry of a case
+type ManifestCase struct {
+	CaseID      string   `json:"case_id"`
+	Name        string   `json:"name"`
+	Emoji       string   `json:"emoji"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Activation  string   `json:"activation"`
+	Insights    []string `json:"insights,omitempty"`
+	Race        *Race    `json:"race,omitempty"`
+}
+
+// GenerateCase generates a case's dataset and trains the neuron on it
+func GenerateCase(spec CaseSpec) (NeuronTrainingCase, error) {
+	dataset, err := GenerateDataset(spec.DataConfig);
+	if err != nil {
+		return NeuronTrainingCase{}, fmt.Errorf("failed to generate data for case %s: %w", spec.CaseID, err);
+	}
+
+	snapshots := Train(dataset, spec.InitParams, spec.Training);
+
+	return NeuronTrainingCase{
+		CaseID:      spec.CaseID,
+		Description: spec.Description,
+		Category:    spec.Category,
+		Activation:  spec.Training.Activation,
+		Dataset:     dataset,
+		InitParams:  spec.InitParams,
+		FinalParams: snapshots[len(snapshots)-1].Params,
+		Config:      spec.Training,
+		Snapshots:   snapshots,
+	}, nil;
+}
+
+// MeasureSnapshots measures a Phase 3 run for checking case expectations. The
+// saturation and dead-ReLU fractions are taken over the points of the last step.
+func MeasureSnapshots(snapshots []NeuronSnapshot) expect.Metrics {
+	t := TraceSnapshots(snapshots);
+	m := expect.Measure(t.Loss, t.Params);
+	if len(snapshots) == 0 {
+		return m;
+	}
+	m.SaturationFraction = t.Saturated[len(t.Saturated)-1];
+	if t.Activation == "relu" {
+		m.DeadReLUFraction = m.SaturationFraction;
+	}
+	return m;
+}
+
+// CheckCase checks a case's expectations against its snapshots
+func CheckCase(spec CaseSpec, snapshots []NeuronSnapshot) error {
+	if err := spec.Expect.Check(MeasureSnapshots(snapshots)); err != nil {
+		return fmt.Errorf("case %s no longer demonstrates its insights:\n%w", spec.CaseID, err);
+	}
+	return nil;
+}
+
+// CaseKey returns the result cache key of a case's snapshots. Only the data,
+// initialization and training configs affect the snapshots.
+func CaseKey(spec CaseSpec) (string, error) {
+	return cache.Key(cache.KeyInput{
+		Phase:          "phase3-case",
+		DataConfig:     spec.DataConfig,
+		TrainingConfig: spec.Training,
+		Extra:          spec.InitParams,
+	});
+}
+
 // GenerateAllCases generates all pre-computed Phase 3 cases
 func GenerateAllCases(outputDir string) error {
+	return GenerateAllCasesCached(outputDir, nil);
+}
+
+// GenerateAllCasesCached generates all Phase 3 cases, reusing results from store.
+// Cases whose result is cached and whose snapshots file exists are skipped.
+func GenerateAllCasesCached(outputDir string, store *cache.Store) error {
 	fmt.Println("Generating Phase 3 cases...");
 
-	cases := []struct {
-		CaseID      string
-		Description string
-		Category    string
-		Generator   func() NeuronTrainingCase
-	}{
-		{
-			CaseID:      "sigmoid-vanishing",
-			Description: "Large initialization leads to saturation and vanishing gradients",
-			Category:    "saturation",
-			Generator:   generateSigmoidVanishing,
-		},
-		{
-			CaseID:      "sigmoid-optimal",
-			Description: "Small initialization keeps sigmoid in active region",
-			Category:    "optimal",
-			Generator:   generateSigmoidOptimal,
-		},
-		{
-			CaseID:      "relu-dying",
-			Description: "Negative initialization causes ReLU to die (outputs zero forever)",
-			Category:    "dying-relu",
-			Generator:   generateReLUDying,
-		},
-		{
-			CaseID:      "relu-optimal",
-			Description: "Positive initialization allows ReLU to converge quickly",
-			Category:    "optimal",
-			Generator:   generateReLUOptimal,
-		},
-		{
-			CaseID:      "tanh-saturation",
-			Description: "Large initialization pushes tanh into saturation zones",
-			Category:    "saturation",
-			Generator:   generateTanhSaturation,
-		},
-		{
-			CaseID:      "tanh-optimal",
-			Description: "Centered initialization keeps tanh active and converging",
-			Category:    "optimal",
-			Generator:   generateTanhOptimal,
-		},
-		{
-			CaseID:      "activation-comparison",
-			Description: "Same data and initialization, different activation functions",
-			Category:    "comparison",
-			Generator:   generateActivationComparison,
-		},
-		{
-			CaseID:      "lr-saturation-interaction",
-			Description: "High learning rate causes oscillations into saturation",
-			Category:    "saturation",
-			Generator:   generateLRSaturation,
-		},
-	};
+	cases := Cases();
 
-	for _, caseSpec := range cases {
+	for i, caseSpec := range cases {
 		fmt.Printf("  Generating case: %s\n", caseSpec.CaseID);
 
-		trainingCase := caseSpec.Generator();
-		trainingCase.CaseID = caseSpec.CaseID;
-		trainingCase.Description = caseSpec.Description;
-		trainingCase.Category = caseSpec.Category;
+		caseDir := filepath.Join(outputDir, caseSpec.CaseID);
+		snapshotsPath := filepath.Join(caseDir, "snapshots.json");
+
+		// Train the case, unless an identical result is cached
+		key, err := CaseKey(caseSpec);
+		if err != nil {
+			return fmt.Errorf("failed to hash case %s: %w", caseSpec.CaseID, err);
+		}
+		data, hit, err := store.GetOrCompute(key, func() (interface{}, error) {
+			return GenerateCase(caseSpec);
+		});
+		if err != nil {
+			return fmt.Errorf("failed to generate case %s: %w", caseSpec.CaseID, err);
+		}
+		var trained NeuronTrainingCase;
+		if err := json.Unmarshal(data, &trained); err != nil {
+			return fmt.Errorf("failed to decode case %s: %w", caseSpec.CaseID, err);
+		}
+		if err := CheckCase(caseSpec, trained.Snapshots); err != nil {
+			return err;
+		}
+		if err := generateRace(caseDir, caseSpec, store); err != nil {
+			return err;
+		}
+		// Cases without written insights get the observed ones
+		if len(caseSpec.Insights) == 0 {
+			cases[i].Insights = insight.Messages(insight.Analyze(TraceSnapshots(trained.Snapshots)));
+		}
+		if _, statErr := os.Stat(snapshotsPath); hit && statErr == nil {
+			fmt.Printf("    ✓ Unchanged, skipped %s\n", snapshotsPath);
+			continue;
+		}
 
 		// Create output directory
-		caseDir := filepath.Join(outputDir, caseSpec.CaseID);
-		err := os.MkdirAll(caseDir, 0755);
+		err = os.MkdirAll(caseDir, 0755);
 		if err != nil {
 			return fmt.Errorf("failed to create directory for case %s: %w", caseSpec.CaseID, err);
 		}
 
 		// Write snapshots.json
-		snapshotsPath := filepath.Join(caseDir, "snapshots.json");
-		err = writeJSON(snapshotsPath, trainingCase);
+		err = cache.WriteIndented(snapshotsPath, data);
 		if err != nil {
 			return fmt.Errorf("failed to write snapshots for case %s: %w", caseSpec.CaseID, err);
 		}
@@ -92,7 +169,27 @@ func GenerateAllCases(outputDir string) error {
 		fmt.Printf("    ✓ Wrote %s (%.1f KB)\n", snapshotsPath, float64(getFileSize(snapshotsPath))/1024.0);
 	}
 
+	// Write manifest.json
+	manifest := CaseManifest{Version: "1.0"};
+	for _, c := range cases {
+		manifest.Cases = append(manifest.Cases, ManifestCase{
+			CaseID:      c.CaseID,
+			Name:        c.Name,
+			Emoji:       c.Emoji,
+			Description: c.Description,
+			Category:    c.Category,
+			Activation:  c.Activation,
+			Insights:    c.Insights,
+			Race:        c.Race,
+		});
+	}
+	manifestPath := filepath.Join(outputDir, "manifest.json");
+	if err := writeJSON(manifestPath, manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err);
+	}
+
 	fmt.Printf("✓ Generated %d Phase 3 cases\n", len(cases));
+	fmt.Printf("✓ Saved manifest to %s\n", manifestPath);
 	return nil;
 }
 
@@ -117,237 +214,3 @@ func getFileSize(path string) int64 {
 	}
 	return info.Size();
 }
-
-// Case 1: Sigmoid Vanishing - Large init → saturation → gradients vanish
-func generateSigmoidVanishing() NeuronTrainingCase {
-	// Large initial weights push z into extreme regions
-	initParams := NeuronParams{
-		W: []float64{5.0, 5.0}, // Large weights
-		B: 2.0,                  // Positive bias pushes z higher
-	};
-
-	// Simple linear target
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 100);
-
-	config := TrainingConfig{
-		LearningRate: 0.01,
-		NumSteps:     200,
-		Activation:   "sigmoid",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 2: Sigmoid Optimal - Small init → active region → smooth convergence
-func generateSigmoidOptimal() NeuronTrainingCase {
-	// Small initial weights keep z in active region
-	initParams := NeuronParams{
-		W: []float64{0.1, 0.1},
-		B: 0.0,
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 101);
-
-	config := TrainingConfig{
-		LearningRate: 0.1, // Can use higher LR with small init
-		NumSteps:     200,
-		Activation:   "sigmoid",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 3: ReLU Dying - Negative init → z < 0 → ReLU outputs zero forever
-func generateReLUDying() NeuronTrainingCase {
-	// Negative initialization causes z < 0 for all points
-	initParams := NeuronParams{
-		W: []float64{-2.0, -2.0}, // Negative weights
-		B: -5.0,                   // Large negative bias
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 102);
-
-	config := TrainingConfig{
-		LearningRate: 0.01,
-		NumSteps:     200,
-		Activation:   "relu",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 4: ReLU Optimal - Positive init → active → fast convergence
-func generateReLUOptimal() NeuronTrainingCase {
-	// Positive initialization keeps ReLU active
-	initParams := NeuronParams{
-		W: []float64{0.1, 0.1},
-		B: 0.5, // Positive bias helps keep z > 0
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 103);
-
-	config := TrainingConfig{
-		LearningRate: 0.1,
-		NumSteps:     200,
-		Activation:   "relu",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 5: Tanh Saturation - Large init → saturation (similar to sigmoid)
-func generateTanhSaturation() NeuronTrainingCase {
-	// Large initial weights push tanh into saturation
-	initParams := NeuronParams{
-		W: []float64{4.0, 4.0},
-		B: 1.0,
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 104);
-
-	config := TrainingConfig{
-		LearningRate: 0.01,
-		NumSteps:     200,
-		Activation:   "tanh",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 6: Tanh Optimal - Centered init → good convergence
-func generateTanhOptimal() NeuronTrainingCase {
-	// Small centered initialization
-	initParams := NeuronParams{
-		W: []float64{0.1, 0.1},
-		B: 0.0,
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 105);
-
-	config := TrainingConfig{
-		LearningRate: 0.1,
-		NumSteps:     200,
-		Activation:   "tanh",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 7: Activation Comparison - Same data/init, different activations
-// Note: This returns sigmoid version; frontend will compare with other cases
-func generateActivationComparison() NeuronTrainingCase {
-	// Same initialization for all activations
-	initParams := NeuronParams{
-		W: []float64{0.2, 0.2},
-		B: 0.1,
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 106);
-
-	config := TrainingConfig{
-		LearningRate: 0.05,
-		NumSteps:     200,
-		Activation:   "sigmoid", // This case uses sigmoid; compare with relu/tanh cases
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
-
-// Case 8: LR-Saturation Interaction - High LR oscillates into saturation
-func generateLRSaturation() NeuronTrainingCase {
-	// Moderate initialization with high learning rate
-	initParams := NeuronParams{
-		W: []float64{1.0, 1.0},
-		B: 0.5,
-	};
-
-	wTrue := []float64{0.5, 0.8};
-	bTrue := 0.3;
-	dataset := GenerateLinearDataset2D(50, wTrue, bTrue, 0.1, [2][2]float64{{-1, 1}, {-1, 1}}, 107);
-
-	config := TrainingConfig{
-		LearningRate: 0.5, // Very high LR causes oscillations
-		NumSteps:     200,
-		Activation:   "sigmoid",
-	};
-
-	snapshots := Train(dataset, initParams, config);
-
-	return NeuronTrainingCase{
-		Dataset:     dataset,
-		InitParams:  initParams,
-		FinalParams: snapshots[len(snapshots)-1].Params,
-		Config:      config,
-		Snapshots:   snapshots,
-	};
-}
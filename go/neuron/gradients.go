@@ -4,6 +4,11 @@ package neuron
 // Chain rule: ∂L/∂w = ∂L/∂a × ∂a/∂z × ∂z/∂w
 //             ∂L/∂b = ∂L/∂a × ∂a/∂z × ∂z/∂b
 func ComputeGradients(dataset []DataPoint2DNeuron, params NeuronParams, activation string) (NeuronGrads, []PointSnapshotNeuron) {
+	return ComputeGradientsWith(dataset, params, NewActivationFunc(activation, 0));
+}
+
+// ComputeGradientsWith computes the gradients like ComputeGradients, with the activation's α
+func ComputeGradientsWith(dataset []DataPoint2DNeuron, params NeuronParams, activation ActivationFunc) (NeuronGrads, []PointSnapshotNeuron) {
 	numPoints := len(dataset);
 	numFeatures := len(params.W);
 
@@ -17,7 +22,7 @@ func ComputeGradients(dataset []DataPoint2DNeuron, params NeuronParams, activati
 	// Iterate through each data point
 	for i, point := range dataset {
 		// Forward pass
-		z, a := Forward(point.X, params, activation);
+		z, a := ForwardWith(point.X, params, activation);
 
 		// Compute loss for this point
 		loss := Loss(a, point.Y);
@@ -27,7 +32,7 @@ func ComputeGradients(dataset []DataPoint2DNeuron, params NeuronParams, activati
 		dL_da := 2.0 * (a - point.Y);
 
 		// 2. ∂a/∂z = σ'(z)
-		da_dz := ApplyActivationDerivative(z, activation);
+		da_dz := activation.Derivative(z);
 
 		// 3. ∂L/∂z = ∂L/∂a × ∂a/∂z
 		dL_dz := dL_da * da_dz;
@@ -50,7 +55,7 @@ func ComputeGradients(dataset []DataPoint2DNeuron, params NeuronParams, activati
 		gradB += dL_db;
 
 		// Check if this point is in saturation
-		inSaturation := IsSaturated(da_dz);
+		inSaturation := activation.Saturated(z, da_dz);
 
 		// Store per-point details
 		pointDetails[i] = PointSnapshotNeuron{
@@ -87,6 +92,12 @@ func ComputeGradients(dataset []DataPoint2DNeuron, params NeuronParams, activati
 // ComputeChainRuleBreakdown computes the chain rule breakdown for visualization
 // Shows: dL/da × da/dz × dz/dparam = dL/dparam for each parameter
 func ComputeChainRuleBreakdown(dataset []DataPoint2DNeuron, params NeuronParams, activation string) ChainRuleViz {
+	return ComputeChainRuleBreakdownWith(dataset, params, NewActivationFunc(activation, 0));
+}
+
+// ComputeChainRuleBreakdownWith computes the chain rule breakdown like
+// ComputeChainRuleBreakdown, with the activation's α
+func ComputeChainRuleBreakdownWith(dataset []DataPoint2DNeuron, params NeuronParams, activation ActivationFunc) ChainRuleViz {
 	numPoints := len(dataset);
 	numFeatures := len(params.W);
 
@@ -97,11 +108,11 @@ func ComputeChainRuleBreakdown(dataset []DataPoint2DNeuron, params NeuronParams,
 
 	for _, point := range dataset {
 		// Forward pass
-		z, a := Forward(point.X, params, activation);
+		z, a := ForwardWith(point.X, params, activation);
 
 		// Chain rule components
 		dL_da := 2.0 * (a - point.Y);
-		da_dz := ApplyActivationDerivative(z, activation);
+		da_dz := activation.Derivative(z);
 
 		avgDLda += dL_da;
 		avgDaDz += da_dz;
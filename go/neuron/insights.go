@@ -0,0 +1,42 @@
+package neuron
+
+import (
+	"fmt"
+
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+)
+
+// TraceSnapshots converts Phase 3 snapshots for the insight analyzer. A point
+// counts as saturated when it is in the activation's saturation zone (see
+// ActivationFunc.Saturated), which for ReLU means it is dead.
+func TraceSnapshots(snapshots []NeuronSnapshot) insight.Trace {
+	t := insight.Trace{
+		Steps:     make([]int, len(snapshots)),
+		Loss:      make([]float64, len(snapshots)),
+		Params:    make([][]float64, len(snapshots)),
+		Saturated: make([]float64, len(snapshots)),
+	}
+	if len(snapshots) > 0 {
+		t.Activation = snapshots[0].Activation
+		for i := range snapshots[0].Params.W {
+			t.ParamNames = append(t.ParamNames, fmt.Sprintf("w%d", i+1))
+		}
+		t.ParamNames = append(t.ParamNames, "b")
+	}
+	for i, snap := range snapshots {
+		t.Steps[i] = snap.Step
+		t.Loss[i] = snap.Loss
+		t.Params[i] = append(append([]float64(nil), snap.Params.W...), snap.Params.B)
+
+		saturated := 0
+		for _, p := range snap.PointDetails {
+			if p.InSaturation {
+				saturated++
+			}
+		}
+		if len(snap.PointDetails) > 0 {
+			t.Saturated[i] = float64(saturated) / float64(len(snap.PointDetails))
+		}
+	}
+	return t
+}
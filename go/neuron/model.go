@@ -6,6 +6,11 @@ import "math"
 // z = w1*x1 + w2*x2 + b
 // a = σ(z)
 func Forward(x []float64, params NeuronParams, activation string) (z float64, a float64) {
+	return ForwardWith(x, params, NewActivationFunc(activation, 0));
+}
+
+// ForwardWith computes the forward pass like Forward, with the activation's α
+func ForwardWith(x []float64, params NeuronParams, activation ActivationFunc) (z float64, a float64) {
 	// Compute pre-activation: z = w·x + b
 	z = 0.0;
 	for i := 0; i < len(x); i++ {
@@ -14,7 +19,7 @@ func Forward(x []float64, params NeuronParams, activation string) (z float64, a
 	z += params.B;
 
 	// Apply activation function: a = σ(z)
-	a = ApplyActivation(z, activation);
+	a = activation.Apply(z);
 
 	return z, a;
 }
@@ -27,9 +32,14 @@ func Loss(a float64, yTrue float64) float64 {
 
 // ComputeAvgLoss computes the average loss across the dataset
 func ComputeAvgLoss(dataset []DataPoint2DNeuron, params NeuronParams, activation string) float64 {
+	return ComputeAvgLossWith(dataset, params, NewActivationFunc(activation, 0));
+}
+
+// ComputeAvgLossWith computes the average loss like ComputeAvgLoss, with the activation's α
+func ComputeAvgLossWith(dataset []DataPoint2DNeuron, params NeuronParams, activation ActivationFunc) float64 {
 	totalLoss := 0.0;
 	for _, point := range dataset {
-		_, a := Forward(point.X, params, activation);
+		_, a := ForwardWith(point.X, params, activation);
 		totalLoss += Loss(a, point.Y);
 	}
 	return totalLoss / float64(len(dataset));
@@ -51,9 +61,14 @@ func ComputeAvgZ(dataset []DataPoint2DNeuron, params NeuronParams) float64 {
 
 // ComputeAvgA computes the average post-activation across the dataset
 func ComputeAvgA(dataset []DataPoint2DNeuron, params NeuronParams, activation string) float64 {
+	return ComputeAvgAWith(dataset, params, NewActivationFunc(activation, 0));
+}
+
+// ComputeAvgAWith computes the average post-activation like ComputeAvgA, with the activation's α
+func ComputeAvgAWith(dataset []DataPoint2DNeuron, params NeuronParams, activation ActivationFunc) float64 {
 	totalA := 0.0;
 	for _, point := range dataset {
-		_, a := Forward(point.X, params, activation);
+		_, a := ForwardWith(point.X, params, activation);
 		totalA += a;
 	}
 	return totalA / float64(len(dataset));
@@ -61,10 +76,15 @@ func ComputeAvgA(dataset []DataPoint2DNeuron, params NeuronParams, activation st
 
 // ComputeAvgDerivative computes the average σ'(z) across the dataset
 func ComputeAvgDerivative(dataset []DataPoint2DNeuron, params NeuronParams, activation string) float64 {
+	return ComputeAvgDerivativeWith(dataset, params, NewActivationFunc(activation, 0));
+}
+
+// ComputeAvgDerivativeWith computes the average σ'(z) like ComputeAvgDerivative, with the activation's α
+func ComputeAvgDerivativeWith(dataset []DataPoint2DNeuron, params NeuronParams, activation ActivationFunc) float64 {
 	totalDeriv := 0.0;
 	for _, point := range dataset {
-		z, _ := Forward(point.X, params, activation);
-		deriv := ApplyActivationDerivative(z, activation);
+		z, _ := ForwardWith(point.X, params, activation);
+		deriv := activation.Derivative(z);
 		totalDeriv += deriv;
 	}
 	return totalDeriv / float64(len(dataset));
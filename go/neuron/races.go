@@ -0,0 +1,120 @@
+package neuron
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+	"github.com/iOliverNguyen/ml-viz/go/race"
+)
+
+// Race is a comparison case: named runs on the case's dataset, differing by
+// activation, learning rate or initialization, and the criterion that picks the winner
+type Race struct {
+	Winner race.Criterion `json:"winner"`
+	Runs   []RaceRun      `json:"runs"`
+}
+
+// RaceRun is one entrant of a race. Without init_params it starts from the case's.
+type RaceRun struct {
+	Name       string         `json:"name"`
+	InitParams *NeuronParams  `json:"init_params,omitempty"`
+	Training   TrainingConfig `json:"training_config"`
+}
+
+// CaseRace is the generated race of a case
+type CaseRace struct {
+	CaseID string `json:"case_id"`
+	race.Result
+}
+
+// Validate checks the criterion and every run, whose initial weights must
+// number numWeights. A nil race is valid.
+func (r *Race) Validate(numWeights int) error {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, len(r.Runs))
+	for i, run := range r.Runs {
+		names[i] = run.Name
+		if err := ValidateActivation(run.Training); err != nil {
+			return fmt.Errorf("run %s: %w", run.Name, err)
+		}
+		if err := validateTraining(run.Training); err != nil {
+			return fmt.Errorf("run %s: training_config: %w", run.Name, err)
+		}
+		if run.InitParams != nil && len(run.InitParams.W) != numWeights {
+			return fmt.Errorf("run %s: init_params.w must have %d weights", run.Name, numWeights)
+		}
+	}
+	return r.Winner.Validate(names)
+}
+
+// RunRace generates the case's dataset, trains every run of its race on it and ranks them
+func RunRace(spec CaseSpec) (CaseRace, error) {
+	dataset, err := GenerateDataset(spec.DataConfig)
+	if err != nil {
+		return CaseRace{}, fmt.Errorf("failed to generate data for case %s: %w", spec.CaseID, err)
+	}
+	names := make([]string, len(spec.Race.Runs))
+	traces := make([]insight.Trace, len(spec.Race.Runs))
+	for i, run := range spec.Race.Runs {
+		initParams := spec.InitParams
+		if run.InitParams != nil {
+			initParams = *run.InitParams
+		}
+		names[i] = run.Name
+		traces[i] = TraceSnapshots(Train(dataset, initParams, run.Training))
+	}
+	return CaseRace{CaseID: spec.CaseID, Result: race.Compare(spec.Race.Winner, names, traces)}, nil
+}
+
+// RaceKey returns the result cache key of a case's race
+func RaceKey(spec CaseSpec) (string, error) {
+	return cache.Key(cache.KeyInput{
+		Phase:      "phase3-race",
+		DataConfig: spec.DataConfig,
+		Extra:      []interface{}{spec.InitParams, spec.Race},
+	})
+}
+
+// generateRace runs the race of a case, if it has one, checks its expected
+// winner and writes race.json into caseDir
+func generateRace(caseDir string, spec CaseSpec, store *cache.Store) error {
+	if spec.Race == nil {
+		return nil
+	}
+	key, err := RaceKey(spec)
+	if err != nil {
+		return fmt.Errorf("failed to hash the race of case %s: %w", spec.CaseID, err)
+	}
+	data, hit, err := store.GetOrCompute(key, func() (interface{}, error) {
+		return RunRace(spec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run the race of case %s: %w", spec.CaseID, err)
+	}
+	var result CaseRace
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to decode the race of case %s: %w", spec.CaseID, err)
+	}
+	if err := spec.Race.Winner.Check(result.Result); err != nil {
+		return fmt.Errorf("case %s no longer demonstrates its insights:\n%w", spec.CaseID, err)
+	}
+
+	racePath := filepath.Join(caseDir, "race.json")
+	if _, statErr := os.Stat(racePath); hit && statErr == nil {
+		return nil
+	}
+	if err := os.MkdirAll(caseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory for case %s: %w", spec.CaseID, err)
+	}
+	if err := cache.WriteIndented(racePath, data); err != nil {
+		return fmt.Errorf("failed to write the race of case %s: %w", spec.CaseID, err)
+	}
+	fmt.Printf("    ✓ Wrote %s (winner: %s)\n", racePath, result.Winner)
+	return nil
+}
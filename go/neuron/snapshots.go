@@ -1,5 +1,7 @@
 package neuron
 
+import "github.com/iOliverNguyen/ml-viz/go/linesearch"
+
 // NeuronParams represents the parameters of the neuron: w = [w1, w2], b
 type NeuronParams struct {
 	W []float64 `json:"w"` // weights [w1, w2]
@@ -32,12 +34,12 @@ type PointSnapshotNeuron struct {
 	DzDw         []float64 `json:"dz_dw"`         // [∂z/∂w1, ∂z/∂w2] = [x1, x2]
 	DLdw         []float64 `json:"dL_dw"`         // [∂L/∂w1, ∂L/∂w2]
 	DLdb         float64   `json:"dL_db"`         // ∂L/∂b
-	InSaturation bool      `json:"in_saturation"` // whether σ'(z) < 0.01
+	InSaturation bool      `json:"in_saturation"` // whether z is in the activation's saturation zone
 }
 
 // UpdateDetailsNeuron contains the details of the parameter update for this step
 type UpdateDetailsNeuron struct {
-	LearningRate  float64   `json:"learning_rate"`
+	LearningRate  float64   `json:"learning_rate"`      // the step length accepted by the line search, if any
 	GradMagnitude float64   `json:"gradient_magnitude"` // ||∇L||
 	UpdateW       []float64 `json:"update_w"`           // -lr × grad_w
 	UpdateB       float64   `json:"update_b"`           // -lr × grad_b
@@ -68,12 +70,19 @@ type NeuronSnapshot struct {
 	DLdz               float64               `json:"dL_dz"`                // avg ∂L/∂z
 	DLda               float64               `json:"dL_da"`                // avg ∂L/∂a
 	LocalDerivative    float64               `json:"local_derivative"`     // avg σ'(z)
-	Activation         string                `json:"activation"`           // "sigmoid", "relu", "tanh"
-	InSaturationZone   bool                  `json:"in_saturation_zone"`   // whether avg |σ'(z)| < 0.01
+	Activation         string                `json:"activation"`           // one of Activations
+	Alpha              float64               `json:"alpha,omitempty"`      // resolved α of leaky_relu or elu
+	InSaturationZone   bool                  `json:"in_saturation_zone"`   // whether avg z is in the saturation zone, by avg σ'(z)
 	Loss               float64               `json:"loss"`                 // avg loss across dataset
 	PointDetails       []PointSnapshotNeuron `json:"point_details"`        // per-point breakdown
 	UpdateComponents   UpdateDetailsNeuron   `json:"update_components"`    // update details
 	ChainRuleBreakdown ChainRuleViz          `json:"chain_rule_breakdown"` // chain rule for each param
+
+	// Trial steps of the line search; omitted when stepping by the learning rate
+	LineSearch *linesearch.Result `json:"line_search,omitempty"`
+
+	// Points of the mini-batch behind Grads; omitted for full batches
+	Batch []int `json:"batch,omitempty"`
 }
 
 // NeuronTrainingCase represents a complete training case with metadata
@@ -81,7 +90,7 @@ type NeuronTrainingCase struct {
 	CaseID      string           `json:"case_id"`
 	Description string           `json:"description"`
 	Category    string           `json:"category"`    // "saturation", "optimal", "dying-relu", "comparison"
-	Activation  string           `json:"activation"`  // "sigmoid", "relu", "tanh"
+	Activation  string           `json:"activation"`  // one of Activations
 	Dataset     []DataPoint2DNeuron `json:"dataset"`
 	InitParams  NeuronParams     `json:"init_params"`
 	FinalParams NeuronParams     `json:"final_params"`
@@ -94,4 +103,17 @@ type TrainingConfig struct {
 	LearningRate float64 `json:"learning_rate"`
 	NumSteps     int     `json:"num_steps"`
 	Activation   string  `json:"activation"`
+
+	// Alpha is the negative slope of leaky_relu (default 0.01) or the scale of
+	// elu (default 1); 0 uses the default, other activations take none
+	Alpha float64 `json:"alpha,omitempty"`
+
+	// LineSearch picks each step length, starting from LearningRate; nil steps
+	// by LearningRate. The loss is not quadratic, so exact line search is not available.
+	LineSearch *linesearch.Config `json:"line_search,omitempty"`
+
+	// BatchSize > 0 averages each step's gradient over a shuffled mini-batch
+	// of that many points; 0 uses the whole dataset
+	BatchSize   int   `json:"batch_size,omitempty"`
+	ShuffleSeed int64 `json:"shuffle_seed,omitempty"` // seeds the batch order
 }
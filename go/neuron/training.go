@@ -1,9 +1,21 @@
 package neuron
 
-import "math"
+import (
+	"context"
+	"math"
+
+	"github.com/iOliverNguyen/ml-viz/go/linesearch"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+)
 
 // Train performs gradient descent training and captures snapshots at each step
 func Train(dataset []DataPoint2DNeuron, initParams NeuronParams, config TrainingConfig) []NeuronSnapshot {
+	snapshots, _ := TrainContext(context.Background(), dataset, initParams, config);
+	return snapshots;
+}
+
+// TrainContext trains like Train but stops early with ctx.Err() once ctx is done
+func TrainContext(ctx context.Context, dataset []DataPoint2DNeuron, initParams NeuronParams, config TrainingConfig) ([]NeuronSnapshot, error) {
 	params := NeuronParams{
 		W: make([]float64, len(initParams.W)),
 		B: initParams.B,
@@ -11,19 +23,26 @@ func Train(dataset []DataPoint2DNeuron, initParams NeuronParams, config Training
 	copy(params.W, initParams.W);
 
 	snapshots := make([]NeuronSnapshot, config.NumSteps);
+	sampler := minibatch.New(len(dataset), config.BatchSize, config.ShuffleSeed);
+	activation := config.ActivationFunc();
 
 	for step := 0; step < config.NumSteps; step++ {
+		// Stop computing once the caller has given up
+		if err := ctx.Err(); err != nil {
+			return snapshots[:step], err;
+		}
+
 		// Compute gradients and per-point details
-		grads, pointDetails := ComputeGradients(dataset, params, config.Activation);
+		grads, pointDetails := ComputeGradientsWith(dataset, params, activation);
 
 		// Compute average metrics across dataset
 		avgZ := ComputeAvgZ(dataset, params);
-		avgA := ComputeAvgA(dataset, params, config.Activation);
-		avgLoss := ComputeAvgLoss(dataset, params, config.Activation);
-		avgDerivative := ComputeAvgDerivative(dataset, params, config.Activation);
+		avgA := ComputeAvgAWith(dataset, params, activation);
+		avgLoss := ComputeAvgLossWith(dataset, params, activation);
+		avgDerivative := ComputeAvgDerivativeWith(dataset, params, activation);
 
 		// Compute chain rule breakdown
-		chainRuleBreakdown := ComputeChainRuleBreakdown(dataset, params, config.Activation);
+		chainRuleBreakdown := ComputeChainRuleBreakdownWith(dataset, params, activation);
 
 		// Compute average dL/da and dL/dz
 		avgDLda := 0.0;
@@ -36,17 +55,35 @@ func Train(dataset []DataPoint2DNeuron, initParams NeuronParams, config Training
 		avgDLdz /= float64(len(pointDetails));
 
 		// Check if in saturation zone
-		inSaturationZone := IsSaturated(avgDerivative);
+		inSaturationZone := activation.Saturated(avgZ, avgDerivative);
+
+		// Stochastic gradient: the loss and averages stay over the whole dataset
+		batch := sampler.Next();
+		if batch != nil {
+			points := make([]DataPoint2DNeuron, len(batch));
+			for k, i := range batch {
+				points[k] = dataset[i];
+			}
+			grads, _ = ComputeGradientsWith(points, params, activation);
+		}
 
 		// Compute gradient magnitude
 		gradMag := GradientMagnitude(grads);
 
+		// Choose the step length along -∇L
+		lr := config.LearningRate;
+		var search *linesearch.Result;
+		if config.LineSearch.Active() {
+			result := searchLine(dataset, config, params, avgLoss, grads);
+			lr, search = result.Step, &result;
+		}
+
 		// Compute updates
 		updateW := make([]float64, len(params.W));
 		for i := 0; i < len(params.W); i++ {
-			updateW[i] = -config.LearningRate * grads.GradW[i];
+			updateW[i] = -lr * grads.GradW[i];
 		}
-		updateB := -config.LearningRate * grads.GradB;
+		updateB := -lr * grads.GradB;
 
 		// Compute step size (magnitude of update vector)
 		stepSize := 0.0;
@@ -58,7 +95,7 @@ func Train(dataset []DataPoint2DNeuron, initParams NeuronParams, config Training
 
 		// Create update details
 		updateComponents := UpdateDetailsNeuron{
-			LearningRate:  config.LearningRate,
+			LearningRate:  lr,
 			GradMagnitude: gradMag,
 			UpdateW:       updateW,
 			UpdateB:       updateB,
@@ -76,11 +113,14 @@ func Train(dataset []DataPoint2DNeuron, initParams NeuronParams, config Training
 			DLda:               avgDLda,
 			LocalDerivative:    avgDerivative,
 			Activation:         config.Activation,
+			Alpha:              activation.Alpha,
 			InSaturationZone:   inSaturationZone,
 			Loss:               avgLoss,
 			PointDetails:       pointDetails,
 			UpdateComponents:   updateComponents,
 			ChainRuleBreakdown: chainRuleBreakdown,
+			LineSearch:         search,
+			Batch:              batch,
 		};
 
 		// Update parameters
@@ -90,5 +130,30 @@ func Train(dataset []DataPoint2DNeuron, initParams NeuronParams, config Training
 		params.B += updateB;
 	}
 
-	return snapshots;
+	return snapshots, nil;
+}
+
+// searchLine runs a line search along -∇L from params, starting from the learning rate
+func searchLine(dataset []DataPoint2DNeuron, config TrainingConfig, params NeuronParams, loss float64, grads NeuronGrads) linesearch.Result {
+	activation := config.ActivationFunc();
+	// slope returns ∇L·d for the direction d = -grads
+	slope := func(g NeuronGrads) float64 {
+		s := -g.GradB * grads.GradB;
+		for i := range g.GradW {
+			s -= g.GradW[i] * grads.GradW[i];
+		}
+		return s;
+	};
+	return linesearch.Search(config.LineSearch, linesearch.Line{
+		Loss:  loss,
+		Slope: slope(grads),
+		Eval: func(step float64) (float64, float64) {
+			trial := NeuronParams{W: make([]float64, len(params.W)), B: params.B - step*grads.GradB};
+			for i := range params.W {
+				trial.W[i] = params.W[i] - step*grads.GradW[i];
+			}
+			trialGrads, _ := ComputeGradientsWith(dataset, trial, activation);
+			return ComputeAvgLossWith(dataset, trial, activation), slope(trialGrads);
+		},
+	}, config.LearningRate);
 }
@@ -0,0 +1,229 @@
+// Package race compares the runs of a comparison case: several training
+// configs on one shared dataset. Each phase declares its runs and trains them;
+// the package lines up their metrics and declares the winner of the case's
+// criterion, so every phase ranks runs the same way.
+package race
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+)
+
+// Metrics of Criterion.Metric; lower wins
+const (
+	MetricFinalLoss   = "final_loss"    // loss of the last step (the default)
+	MetricMinLoss     = "min_loss"      // lowest loss of the run
+	MetricMeanLoss    = "mean_loss"     // mean loss over the steps: rewards converging early and staying down
+	MetricStepsToLoss = "steps_to_loss" // first step whose loss is at most Target
+)
+
+// NumCheckpoints is the number of aligned steps every standing reports its loss at
+const NumCheckpoints = 11
+
+// Criterion decides the winner of a race. Diverged runs rank last; ties go to
+// the run listed first.
+type Criterion struct {
+	Metric string  `json:"metric"`           // see the Metric constants (default final_loss)
+	Target float64 `json:"target,omitempty"` // loss to reach, for steps_to_loss
+
+	// Expect names the run that should win; generation fails when another does
+	Expect string `json:"expect,omitempty"`
+}
+
+// Validate checks the criterion against the names of the runs, which must be
+// unique and at least two
+func (c Criterion) Validate(names []string) error {
+	switch c.Metric {
+	case "", MetricFinalLoss, MetricMinLoss, MetricMeanLoss:
+	case MetricStepsToLoss:
+		if !(c.Target > 0) {
+			return fmt.Errorf("winner %s needs a positive target", MetricStepsToLoss)
+		}
+	default:
+		return fmt.Errorf("unknown winner metric %q (want %s, %s, %s or %s)", c.Metric,
+			MetricFinalLoss, MetricMinLoss, MetricMeanLoss, MetricStepsToLoss)
+	}
+	if len(names) < 2 {
+		return fmt.Errorf("a race needs at least 2 runs, got %d", len(names))
+	}
+	seen := map[string]bool{}
+	for i, name := range names {
+		if name == "" || seen[name] {
+			return fmt.Errorf("runs[%d] needs a unique name", i)
+		}
+		seen[name] = true
+	}
+	if c.Expect != "" && !seen[c.Expect] {
+		return fmt.Errorf("expected winner %q is not a run", c.Expect)
+	}
+	return nil
+}
+
+// Check returns an error when the race was not won by the expected run
+func (c Criterion) Check(result Result) error {
+	if c.Expect != "" && result.Winner != c.Expect {
+		return fmt.Errorf("%s won the race on %s, expected %s", result.Winner, c.metric(), c.Expect)
+	}
+	return nil
+}
+
+func (c Criterion) metric() string {
+	if c.Metric == "" {
+		return MetricFinalLoss
+	}
+	return c.Metric
+}
+
+// Standing is the result of one run, with the same metrics for every run. A
+// run diverges once its loss exceeds insight.DivergenceFactor times its initial
+// loss, or at its first non-finite loss or parameter; its metrics and curve
+// cover the finite steps.
+type Standing struct {
+	Name        string    `json:"name"`
+	Rank        int       `json:"rank"` // 1 is the winner
+	NumSteps    int       `json:"num_steps"`
+	Diverged    bool      `json:"diverged"`
+	InitialLoss float64   `json:"initial_loss"`
+	FinalLoss   float64   `json:"final_loss"`
+	MinLoss     float64   `json:"min_loss"`
+	MeanLoss    float64   `json:"mean_loss"`
+	FinalParams []float64 `json:"final_params"` // in the order of Result.ParamNames
+
+	// StepsToTarget is the first step whose loss is at most the criterion's
+	// target; -1 when never reached or there is no target
+	StepsToTarget int `json:"steps_to_target"`
+
+	Losses []float64 `json:"losses"` // at Result.Checkpoints, up to the divergence
+	Curve  []float64 `json:"curve"`  // loss of every step, up to the divergence
+}
+
+// Result is a race: the standing of every run, in the order the runs are declared
+type Result struct {
+	Criterion   Criterion  `json:"criterion"`
+	Winner      string     `json:"winner"`
+	ParamNames  []string   `json:"param_names"`
+	Checkpoints []int      `json:"checkpoints"` // steps spread evenly over the shortest run
+	Standings   []Standing `json:"standings"`
+}
+
+// Compare ranks runs by the criterion. traces[k] is the run named names[k].
+func Compare(criterion Criterion, names []string, traces []insight.Trace) Result {
+	result := Result{Criterion: criterion, Standings: make([]Standing, len(traces))}
+	shortest := -1
+	for k, t := range traces {
+		if result.ParamNames == nil {
+			result.ParamNames = t.ParamNames
+		}
+		if shortest < 0 || len(t.Loss) < shortest {
+			shortest = len(t.Loss)
+		}
+		result.Standings[k] = stand(names[k], t, criterion.Target)
+	}
+	result.Checkpoints = checkpoints(max(shortest, 0))
+	for k := range result.Standings {
+		s := &result.Standings[k]
+		s.Losses = []float64{}
+		for _, step := range result.Checkpoints {
+			if step < len(s.Curve) {
+				s.Losses = append(s.Losses, s.Curve[step])
+			}
+		}
+	}
+
+	order := make([]int, len(traces))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return criterion.beats(result.Standings[order[i]], result.Standings[order[j]])
+	})
+	for rank, k := range order {
+		result.Standings[k].Rank = rank + 1
+	}
+	if len(order) > 0 {
+		result.Winner = result.Standings[order[0]].Name
+	}
+	return result
+}
+
+// beats reports whether a ranks before b
+func (c Criterion) beats(a, b Standing) bool {
+	if a.Diverged != b.Diverged {
+		return !a.Diverged
+	}
+	switch c.metric() {
+	case MetricMinLoss:
+		return a.MinLoss < b.MinLoss
+	case MetricMeanLoss:
+		return a.MeanLoss < b.MeanLoss
+	case MetricStepsToLoss:
+		// Runs that never reach the target rank after those that do, by final loss
+		reachedA, reachedB := a.StepsToTarget >= 0, b.StepsToTarget >= 0
+		if reachedA != reachedB {
+			return reachedA
+		}
+		if reachedA {
+			return a.StepsToTarget < b.StepsToTarget
+		}
+	}
+	return a.FinalLoss < b.FinalLoss
+}
+
+// stand measures one run
+func stand(name string, t insight.Trace, target float64) Standing {
+	s := Standing{Name: name, NumSteps: len(t.Loss), StepsToTarget: -1, Curve: []float64{}, FinalParams: []float64{}}
+	for i, loss := range t.Loss {
+		if !finite(loss) || !allFinite(t.Params[i]) {
+			s.Diverged = true
+			break
+		}
+		s.Curve = append(s.Curve, loss)
+		s.FinalParams = t.Params[i]
+		if target > 0 && s.StepsToTarget < 0 && loss <= target {
+			s.StepsToTarget = i
+		}
+	}
+	if len(s.Curve) == 0 {
+		s.Diverged = true
+		return s
+	}
+	s.InitialLoss = s.Curve[0]
+	s.FinalLoss = s.Curve[len(s.Curve)-1]
+	s.MinLoss = s.Curve[0]
+	for _, loss := range s.Curve {
+		s.MinLoss = math.Min(s.MinLoss, loss)
+		s.MeanLoss += loss / float64(len(s.Curve))
+		if s.InitialLoss > 0 && loss > insight.DivergenceFactor*s.InitialLoss {
+			s.Diverged = true
+		}
+	}
+	return s
+}
+
+// checkpoints returns NumCheckpoints steps spread evenly over n steps, first and last included
+func checkpoints(n int) []int {
+	steps := []int{}
+	for i := 0; i < NumCheckpoints && n > 0; i++ {
+		step := int(math.Round(float64(i) * float64(n-1) / float64(NumCheckpoints-1)))
+		if len(steps) == 0 || step != steps[len(steps)-1] {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func allFinite(values []float64) bool {
+	for _, v := range values {
+		if !finite(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/insight"
+	"github.com/iOliverNguyen/ml-viz/go/race"
+)
+
+// Race is a comparison case: named training configs run on the case's dataset,
+// and the criterion that picks the winner
+type Race struct {
+	Winner race.Criterion `json:"winner"`
+	Runs   []RaceRun      `json:"runs"`
+}
+
+// RaceRun is one entrant of a race
+type RaceRun struct {
+	Name     string         `json:"name"`
+	Training TrainingConfig `json:"training_config"`
+}
+
+// CaseRace is the generated race of a case
+type CaseRace struct {
+	CaseID string `json:"case_id"`
+	race.Result
+}
+
+// Validate checks the criterion and every run. A nil race is valid.
+func (r *Race) Validate() error {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, len(r.Runs))
+	for i, run := range r.Runs {
+		names[i] = run.Name
+		if err := validateTraining(run.Training); err != nil {
+			return fmt.Errorf("run %s: training_config: %w", run.Name, err)
+		}
+	}
+	return r.Winner.Validate(names)
+}
+
+// RunRace generates the case's dataset, trains every run of its race on it and ranks them
+func RunRace(caseConfig CaseConfig) (CaseRace, error) {
+	data, err := GenerateRandomData(caseConfig.DataConfig)
+	if err != nil {
+		return CaseRace{}, fmt.Errorf("failed to generate data for case %s: %w", caseConfig.ID, err)
+	}
+	names := make([]string, len(caseConfig.Race.Runs))
+	traces := make([]insight.Trace, len(caseConfig.Race.Runs))
+	for i, run := range caseConfig.Race.Runs {
+		names[i] = run.Name
+		traces[i] = TraceSnapshots(RunTrainingWithDataset(data, run.Training))
+	}
+	return CaseRace{CaseID: caseConfig.ID, Result: race.Compare(caseConfig.Race.Winner, names, traces)}, nil
+}
+
+// RaceKey returns the result cache key of a case's race
+func RaceKey(caseConfig CaseConfig) (string, error) {
+	return cache.Key(cache.KeyInput{
+		Phase:      "phase1-race",
+		DataConfig: caseConfig.DataConfig,
+		Extra:      caseConfig.Race,
+	})
+}
+
+// generateRace runs the race of a case, if it has one, checks its expected
+// winner and writes race.json into caseDir
+func generateRace(caseDir string, caseConfig CaseConfig, store *cache.Store) error {
+	if caseConfig.Race == nil {
+		return nil
+	}
+	key, err := RaceKey(caseConfig)
+	if err != nil {
+		return fmt.Errorf("failed to hash the race of case %s: %w", caseConfig.ID, err)
+	}
+	data, hit, err := store.GetOrCompute(key, func() (interface{}, error) {
+		return RunRace(caseConfig)
+	})
+	if err != nil {
+		return err
+	}
+	var result CaseRace
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to decode the race of case %s: %w", caseConfig.ID, err)
+	}
+	if err := caseConfig.Race.Winner.Check(result.Result); err != nil {
+		return fmt.Errorf("case %s no longer demonstrates its insights:\n%w", caseConfig.ID, err)
+	}
+
+	racePath := filepath.Join(caseDir, "race.json")
+	if _, statErr := os.Stat(racePath); hit && statErr == nil {
+		return nil
+	}
+	if err := os.MkdirAll(caseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create case directory: %w", err)
+	}
+	if err := cache.WriteIndented(racePath, data); err != nil {
+		return fmt.Errorf("failed to write race: %w", err)
+	}
+	fmt.Printf("  ✓ Saved race to %s (winner: %s)\n", racePath, result.Winner)
+	return nil
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+)
+
+// Run is one training request stored by the server, addressable by ID
+type Run struct {
+	ID            string                    `json:"id"`
+	Phase         string                    `json:"phase"` // "phase1", "phase2", "phase3"
+	CreatedAt     time.Time                 `json:"created_at"`
+	NumSteps      int                       `json:"num_steps"`
+	Dataset       interface{}               `json:"dataset,omitempty"`        // training data for phases 2 and 3
+	LossGrid      *linear.LossGrid          `json:"loss_grid,omitempty"`      // Phase 2 only
+	Contours      []linear.Contour          `json:"contours,omitempty"`       // Phase 2 only, when requested
+	GradientField *linear.GradientField     `json:"gradient_field,omitempty"` // Phase 2 only, when requested
+	DatasetStats  *linear.DatasetStats2D    `json:"dataset_stats,omitempty"`  // Phase 2 only
+	Hessian       *linear.HessianAnalysis   `json:"hessian,omitempty"`        // Phase 2 only
+	Trajectory    *linear.TrajectoryMetrics `json:"trajectory,omitempty"`     // Phase 2 only
+	Features      *linear.FeatureRun        `json:"features,omitempty"`       // Phase 2 N-feature runs only
+	ResultKey     string                    `json:"result_key,omitempty"`     // content hash of the training inputs, used as ETag
+	Snapshots     interface{}               `json:"snapshots"`
+}
+
+// RunSummary is the listing view of a run (everything except the snapshots)
+type RunSummary struct {
+	ID        string    `json:"id"`
+	Phase     string    `json:"phase"`
+	CreatedAt time.Time `json:"created_at"`
+	NumSteps  int       `json:"num_steps"`
+}
+
+// Summary returns the listing view of the run
+func (r *Run) Summary() RunSummary {
+	return RunSummary{
+		ID:        r.ID,
+		Phase:     r.Phase,
+		CreatedAt: r.CreatedAt,
+		NumSteps:  r.NumSteps,
+	}
+}
+
+// RunRetention controls how long runs are kept by a RunRegistry
+type RunRetention struct {
+	MaxRuns int           `json:"max_runs"` // oldest runs are evicted beyond this count (0 = unlimited)
+	TTL     time.Duration `json:"ttl"`      // runs older than this are evicted (0 = never expire)
+}
+
+// DefaultRunRetention returns the default retention policy
+func DefaultRunRetention() RunRetention {
+	return RunRetention{
+		MaxRuns: 100,
+		TTL:     time.Hour,
+	}
+}
+
+// RunRegistry stores training runs by ID so concurrent users don't overwrite each other
+type RunRegistry struct {
+	mu        sync.Mutex
+	retention RunRetention
+	runs      map[string]*Run
+	order     []string // run IDs, oldest first
+	now       func() time.Time
+}
+
+// NewRunRegistry creates an empty registry with the given retention policy
+func NewRunRegistry(retention RunRetention) *RunRegistry {
+	return &RunRegistry{
+		retention: retention,
+		runs:      make(map[string]*Run),
+		now:       time.Now,
+	}
+}
+
+// Add assigns the run an ID and creation time, stores it and returns it
+func (r *RunRegistry) Add(run Run) *Run {
+	run.ID = newRunID()
+	run.CreatedAt = r.now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs[run.ID] = &run
+	r.order = append(r.order, run.ID)
+	r.pruneLocked()
+
+	return &run
+}
+
+// Get returns the run with the given ID
+func (r *RunRegistry) Get(id string) (*Run, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+	run, ok := r.runs[id]
+	return run, ok
+}
+
+// Latest returns the most recently added run
+func (r *RunRegistry) Latest() (*Run, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+	if len(r.order) == 0 {
+		return nil, false
+	}
+	return r.runs[r.order[len(r.order)-1]], true
+}
+
+// List returns summaries of all retained runs, newest first
+func (r *RunRegistry) List() []RunSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneLocked()
+	summaries := make([]RunSummary, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		summaries = append(summaries, r.runs[r.order[i]].Summary())
+	}
+	return summaries
+}
+
+// Delete removes the run with the given ID, reporting whether it existed
+func (r *RunRegistry) Delete(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.runs[id]; !ok {
+		return false
+	}
+	delete(r.runs, id)
+	for i, existing := range r.order {
+		if existing == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// pruneLocked evicts expired runs and runs beyond MaxRuns. Caller must hold r.mu.
+func (r *RunRegistry) pruneLocked() {
+	// Evict expired runs (order is oldest first, so stop at the first live one)
+	if r.retention.TTL > 0 {
+		cutoff := r.now().Add(-r.retention.TTL)
+		expired := 0
+		for _, id := range r.order {
+			if r.runs[id].CreatedAt.After(cutoff) {
+				break
+			}
+			delete(r.runs, id)
+			expired++
+		}
+		r.order = r.order[expired:]
+	}
+
+	// Evict oldest runs beyond the cap
+	if r.retention.MaxRuns > 0 {
+		for len(r.order) > r.retention.MaxRuns {
+			delete(r.runs, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+}
+
+// newRunID returns a random 16-character hex ID
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b) // crypto/rand does not fail on supported platforms
+	return hex.EncodeToString(b)
+}
@@ -8,24 +8,39 @@ package core
 //   cd js && pnpm dev
 //
 // The server is only needed if you want to use the API endpoints:
-//   - GET  /api/snapshots        - Load pre-computed snapshots from file
-//   - POST /api/dataset/random   - Generate random data + train on-the-fly
-//   - POST /api/dataset/custom   - Train with user-provided custom data
+//   - GET    /api/snapshots                    - Snapshots of the latest run (or output/snapshots.json)
+//   - POST   /api/dataset/random               - Generate random data + train on-the-fly
+//   - POST   /api/dataset/custom               - Train with user-provided custom data
+//   - POST   /api/phase2/dataset/random        - Phase 2: generate 2D data + train, with loss grid
+//   - POST   /api/phase2/dataset/custom        - Phase 2: train on custom 2D data, with loss grid
+//   - POST   /api/phase2/features/random       - Phase 2: generate N-feature data + train, projected onto a plane
+//   - POST   /api/phase2/features/custom       - Phase 2: train on custom N-feature data, projected onto a plane
+//   - POST   /api/phase3/dataset/random        - Phase 3: generate data + train a single neuron
+//   - POST   /api/phase3/dataset/custom        - Phase 3: train a single neuron on custom data
+//   - GET    /api/cases                        - List cases of all phases (?phase=&category=&activation=)
+//   - GET    /api/cases/{phase}/{id}           - Fetch one case's config
+//   - GET    /api/cases/{phase}/{id}/snapshots - Fetch one case's snapshots (generated on demand)
+//   - GET    /api/cases/{phase}/{id}/race      - Fetch a comparison case's race (generated on demand)
+//   - GET    /api/cases/{phase}/{id}/export    - Download one case as CSV/columnar (?table=steps|points&format=csv|columnar)
+//   - GET    /api/cases/{phase}/{id}/insights  - Observations derived from one case's snapshots
+//   - GET    /api/runs                         - List retained runs
+//   - GET    /api/runs/{id}                    - Fetch one run with its snapshots
+//   - GET    /api/runs/{id}/export             - Download one run as CSV/columnar (?table=steps|points&format=csv|columnar)
+//   - GET    /api/runs/{id}/insights           - Observations derived from one run's snapshots
+//   - DELETE /api/runs/{id}                    - Delete one run
 //
 // However, the frontend now has equivalent functionality client-side.
 
 import (
 	"encoding/json"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
-	"sync"
-)
+	"strings"
 
-// Server state to store current snapshots
-var (
-	currentSnapshots []Snapshot
-	snapshotsMutex   sync.RWMutex
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
 )
 
 // TrainingRequest combines dataset and training configuration
@@ -40,127 +55,326 @@ type RandomDataRequest struct {
 	TrainingConfig TrainingConfig `json:"training_config"`
 }
 
-// StartServer starts an HTTP server that serves training snapshots
-func StartServer(addr string) error {
-	// Initialize with default snapshots from file if available
-	if data, err := os.ReadFile("output/snapshots.json"); err == nil {
-		json.Unmarshal(data, &currentSnapshots)
+// ServerConfig configures a Server
+type ServerConfig struct {
+	SnapshotsPath string       // fallback file for GET /api/snapshots when no run exists
+	CasesDir      string       // directory holding the pre-computed case libraries ("" = generate all on demand)
+	CacheSize     int          // training results kept in memory (0 = unlimited)
+	CacheDir      string       // directory for on-disk training results ("" = memory only)
+	Retention     RunRetention // how long training runs are kept
+	Limits        Limits       // caps on dataset size, steps, body size and request time
+	StaticFS      fs.FS        // built frontend (js/dist) to serve alongside the API, nil = API only
+	StaticPrefix  string       // URL prefix the frontend is built for (vite "base")
+}
+
+// DefaultServerConfig returns the default server configuration
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		SnapshotsPath: "output/snapshots.json",
+		CasesDir:      "js/public",
+		CacheSize:     256,
+		Retention:     DefaultRunRetention(),
+		Limits:        DefaultLimits(),
+		StaticPrefix:  "/ml-viz/",
 	}
+}
 
-	mux := http.NewServeMux()
+// Server serves the training API. Each training request is stored as its own run.
+type Server struct {
+	config  ServerConfig
+	runs    *RunRegistry
+	cases   *CaseLibrary
+	results *cache.Store
+	mux     *http.ServeMux
+}
 
-	// CORS middleware
-	corsMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// NewServer creates a Server with its routes registered
+func NewServer(config ServerConfig) *Server {
+	results := cache.NewStore(config.CacheSize, config.CacheDir)
+	s := &Server{
+		config:  config,
+		runs:    NewRunRegistry(config.Retention),
+		cases:   NewCaseLibrary(config.CasesDir, results),
+		results: results,
+		mux:     http.NewServeMux(),
+	}
 
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
+	s.mux.HandleFunc("/api/snapshots", corsMiddleware(s.handleSnapshots))
+	s.mux.HandleFunc("/api/dataset/random", corsMiddleware(s.withLimits(s.handleRandomDataset)))
+	s.mux.HandleFunc("/api/dataset/custom", corsMiddleware(s.withLimits(s.handleCustomDataset)))
+	s.mux.HandleFunc("/api/phase2/dataset/random", corsMiddleware(s.withLimits(s.handlePhase2Random)))
+	s.mux.HandleFunc("/api/phase2/dataset/custom", corsMiddleware(s.withLimits(s.handlePhase2Custom)))
+	s.mux.HandleFunc("/api/phase2/features/random", corsMiddleware(s.withLimits(s.handlePhase2FeaturesRandom)))
+	s.mux.HandleFunc("/api/phase2/features/custom", corsMiddleware(s.withLimits(s.handlePhase2FeaturesCustom)))
+	s.mux.HandleFunc("/api/phase3/dataset/random", corsMiddleware(s.withLimits(s.handlePhase3Random)))
+	s.mux.HandleFunc("/api/phase3/dataset/custom", corsMiddleware(s.withLimits(s.handlePhase3Custom)))
+	s.mux.HandleFunc("/api/cases", corsMiddleware(s.handleCases))
+	s.mux.HandleFunc("/api/cases/", corsMiddleware(s.handleCase))
+	s.mux.HandleFunc("/api/runs", corsMiddleware(s.handleRuns))
+	s.mux.HandleFunc("/api/runs/", corsMiddleware(s.handleRun))
 
-			next(w, r)
+	// Serve the built frontend (app, cases and content JSON) on the same port
+	if config.StaticFS != nil {
+		prefix := config.StaticPrefix
+		if prefix == "" {
+			prefix = "/"
+		}
+		s.mux.Handle(prefix, http.StripPrefix(strings.TrimSuffix(prefix, "/"), http.FileServer(http.FS(config.StaticFS))))
+		if prefix != "/" {
+			s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					http.NotFound(w, r)
+					return
+				}
+				http.Redirect(w, r, prefix, http.StatusFound)
+			})
 		}
 	}
 
-	// GET /api/snapshots - Get current training snapshots
-	mux.HandleFunc("/api/snapshots", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+	return s
+}
 
-		snapshotsMutex.RLock()
-		defer snapshotsMutex.RUnlock()
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
 
-		if len(currentSnapshots) == 0 {
-			// Try to read from file as fallback
-			data, err := os.ReadFile("output/snapshots.json")
-			if err != nil {
-				http.Error(w, "Snapshots not found. Run training first.", 404)
-				return
-			}
-			w.Write(data)
-			return
-		}
+// Runs returns the server's run registry
+func (s *Server) Runs() *RunRegistry {
+	return s.runs
+}
 
-		json.NewEncoder(w).Encode(currentSnapshots)
-	}))
+// StartServer starts an HTTP server with the default configuration
+func StartServer(addr string) error {
+	return ListenAndServe(addr, DefaultServerConfig())
+}
 
-	// POST /api/dataset/random - Generate random data and train
-	mux.HandleFunc("/api/dataset/random", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+// ListenAndServe starts an HTTP server with the given configuration
+func ListenAndServe(addr string, config ServerConfig) error {
+	log.Printf("Server listening on %s", addr)
+	if config.StaticFS != nil {
+		log.Printf("Serving frontend at %s", config.StaticPrefix)
+	}
+	log.Println("Endpoints:")
+	log.Println("  GET    /api/snapshots                    - Get snapshots of the latest run")
+	log.Println("  POST   /api/dataset/random               - Generate random data and train")
+	log.Println("  POST   /api/dataset/custom               - Train with custom data")
+	log.Println("  POST   /api/phase2/dataset/random        - Phase 2: generate 2D data and train")
+	log.Println("  POST   /api/phase2/dataset/custom        - Phase 2: train with custom 2D data")
+	log.Println("  POST   /api/phase2/features/random       - Phase 2: generate N-feature data and train")
+	log.Println("  POST   /api/phase2/features/custom       - Phase 2: train with custom N-feature data")
+	log.Println("  POST   /api/phase3/dataset/random        - Phase 3: generate data and train a neuron")
+	log.Println("  POST   /api/phase3/dataset/custom        - Phase 3: train a neuron with custom data")
+	log.Println("  GET    /api/cases                        - List cases")
+	log.Println("  GET    /api/cases/{phase}/{id}           - Get a case's config")
+	log.Println("  GET    /api/cases/{phase}/{id}/snapshots - Get a case's snapshots")
+	log.Println("  GET    /api/cases/{phase}/{id}/export    - Download a case as CSV or columnar")
+	log.Println("  GET    /api/cases/{phase}/{id}/insights  - Get observations of a case")
+	log.Println("  GET    /api/runs                         - List runs")
+	log.Println("  GET    /api/runs/{id}                    - Get a run")
+	log.Println("  GET    /api/runs/{id}/export             - Download a run as CSV or columnar")
+	log.Println("  GET    /api/runs/{id}/insights           - Get observations of a run")
+	log.Println("  DELETE /api/runs/{id}                    - Delete a run")
+	return http.ListenAndServe(addr, NewServer(config))
+}
 
-		var req RandomDataRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+// corsMiddleware allows the API to be called from the dev frontend
+func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
-		// Generate random data
-		data, err := GenerateRandomData(req.DataConfig)
-		if err != nil {
-			http.Error(w, "Failed to generate data: "+err.Error(), http.StatusBadRequest)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		// Validate dataset
-		if err := ValidateDataset(data); err != nil {
-			http.Error(w, "Invalid dataset: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+		next(w, r)
+	}
+}
 
-		// Run training
-		snapshots := RunTrainingWithDataset(data, req.TrainingConfig)
+// writeJSONResponse encodes v as the JSON response body
+func writeJSONResponse(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
 
-		// Update server state
-		snapshotsMutex.Lock()
-		currentSnapshots = snapshots
-		snapshotsMutex.Unlock()
+// GET /api/snapshots - Get snapshots of the latest run
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	if run, ok := s.runs.Latest(); ok {
+		writeJSONResponse(w, http.StatusOK, run.Snapshots)
+		return
+	}
 
-		// Return snapshots
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(snapshots)
-	}))
+	// Fall back to the snapshots written by the CLI
+	data, err := os.ReadFile(s.config.SnapshotsPath)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Snapshots not found. Run training first.", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
 
-	// POST /api/dataset/custom - Train with custom data
-	mux.HandleFunc("/api/dataset/custom", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+// POST /api/dataset/random - Generate random data and train
+func (s *Server) handleRandomDataset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
 
-		var req TrainingRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+	var req RandomDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
-		// Validate dataset
-		if err := ValidateDataset(req.Data); err != nil {
-			http.Error(w, "Invalid dataset: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+	// Check limits before allocating anything
+	if err := s.config.Limits.CheckTraining(req.DataConfig.NumPoints, req.TrainingConfig.Steps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
 
-		// Run training
-		snapshots := RunTrainingWithDataset(req.Data, req.Config)
+	// Generate random data
+	data, err := GenerateRandomData(req.DataConfig)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to generate data", err)
+		return
+	}
 
-		// Update server state
-		snapshotsMutex.Lock()
-		currentSnapshots = snapshots
-		snapshotsMutex.Unlock()
+	// Validate dataset
+	if err := ValidateDataset(data); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+	if err := req.TrainingConfig.LineSearch.Validate(true); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+	if err := minibatch.Validate(req.TrainingConfig.BatchSize, req.TrainingConfig.LineSearch.Active()); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
 
-		// Return snapshots
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(snapshots)
-	}))
+	// Seed 0 draws a fresh random seed, so only seeded requests are cacheable
+	key := ""
+	if req.DataConfig.Seed != 0 {
+		key = resultKey(cache.KeyInput{Phase: "phase1", DataConfig: req.DataConfig, TrainingConfig: req.TrainingConfig})
+	}
 
-	log.Printf("Server listening on %s", addr)
-	log.Println("Endpoints:")
-	log.Println("  GET  /api/snapshots        - Get current training snapshots")
-	log.Println("  POST /api/dataset/random   - Generate random data and train")
-	log.Println("  POST /api/dataset/custom   - Train with custom data")
-	return http.ListenAndServe(addr, mux)
+	// Run training (or reuse an identical result) and store it as a new run
+	var snapshots []Snapshot
+	err = s.trainCached(w, key, &snapshots, func() (err error) {
+		snapshots, err = RunTrainingWithDatasetContext(r.Context(), data, req.TrainingConfig)
+		return err
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "Training stopped", err)
+		return
+	}
+	run := s.runs.Add(Run{Phase: "phase1", NumSteps: len(snapshots), ResultKey: key, Snapshots: snapshots})
+
+	writeJSONResponse(w, http.StatusCreated, run)
+}
+
+// POST /api/dataset/custom - Train with custom data
+func (s *Server) handleCustomDataset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req TrainingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Validate dataset
+	if err := s.config.Limits.CheckTraining(len(req.Data), req.Config.Steps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := ValidateDataset(req.Data); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+	if err := req.Config.LineSearch.Validate(true); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+	if err := minibatch.Validate(req.Config.BatchSize, req.Config.LineSearch.Active()); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+
+	// Run training (or reuse an identical result) and store it as a new run
+	key := resultKey(cache.KeyInput{Phase: "phase1", Dataset: req.Data, TrainingConfig: req.Config})
+	var snapshots []Snapshot
+	err := s.trainCached(w, key, &snapshots, func() (err error) {
+		snapshots, err = RunTrainingWithDatasetContext(r.Context(), req.Data, req.Config)
+		return err
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "Training stopped", err)
+		return
+	}
+	run := s.runs.Add(Run{Phase: "phase1", NumSteps: len(snapshots), ResultKey: key, Snapshots: snapshots})
+
+	writeJSONResponse(w, http.StatusCreated, run)
+}
+
+// GET /api/runs - List runs, newest first
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, s.runs.List())
+}
+
+// GET|DELETE /api/runs/{id} - Fetch or delete one run
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/runs/")
+	if strings.HasSuffix(id, "/export") {
+		s.handleRunExport(w, r, strings.TrimSuffix(id, "/export"))
+		return
+	}
+	if strings.HasSuffix(id, "/insights") {
+		s.handleRunInsights(w, r, strings.TrimSuffix(id, "/insights"))
+		return
+	}
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		run, ok := s.runs.Get(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Run not found: "+id, nil)
+			return
+		}
+		if run.ResultKey != "" {
+			etag := resultETag(run.ResultKey)
+			w.Header().Set("ETag", etag)
+			if notModified(r, etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		writeJSONResponse(w, http.StatusOK, run)
+	case "DELETE":
+		if !s.runs.Delete(id) {
+			writeError(w, http.StatusNotFound, "Run not found: "+id, nil)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+	}
 }
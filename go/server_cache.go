@@ -0,0 +1,79 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+)
+
+// resultKey hashes the inputs of a training request. It returns "" (no caching)
+// when the inputs cannot be hashed.
+func resultKey(in cache.KeyInput) string {
+	key, err := cache.Key(in)
+	if err != nil {
+		log.Printf("result cache: cannot hash %s request: %v", in.Phase, err)
+		return ""
+	}
+	return key
+}
+
+// trainCached decodes the cached result for key into out, or calls train to
+// fill out and caches it. An empty key always trains. The key is returned to
+// the client as a weak ETag, along with X-Cache: hit or miss.
+func (s *Server) trainCached(w http.ResponseWriter, key string, out interface{}, train func() error) error {
+	if data, ok := s.results.Get(key); ok {
+		if err := json.Unmarshal(data, out); err == nil {
+			setCacheHeaders(w, key, true)
+			return nil
+		}
+	}
+
+	if err := train(); err != nil {
+		return err
+	}
+	if key != "" {
+		data, err := json.Marshal(out)
+		if err == nil {
+			err = s.results.Put(key, data)
+		}
+		if err != nil {
+			log.Printf("result cache: cannot store %s: %v", key, err)
+		}
+	}
+	setCacheHeaders(w, key, false)
+	return nil
+}
+
+// setCacheHeaders reports the result key and whether it came from the cache
+func setCacheHeaders(w http.ResponseWriter, key string, hit bool) {
+	if key == "" {
+		return
+	}
+	w.Header().Set("ETag", resultETag(key))
+	if hit {
+		w.Header().Set("X-Cache", "hit")
+	} else {
+		w.Header().Set("X-Cache", "miss")
+	}
+}
+
+// resultETag returns the ETag of a result key. Keys hash the training inputs,
+// not the response bytes, so every response uses the weak form.
+func resultETag(key string) string {
+	return `W/"` + key + `"`
+}
+
+// notModified reports whether the request's If-None-Match already names etag,
+// comparing weakly as If-None-Match requires
+func notModified(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GET /api/cases?phase=&category=&activation= - List cases across phases
+func (s *Server) handleCases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := CaseFilter{
+		Category:   query.Get("category"),
+		Activation: query.Get("activation"),
+	}
+	if p := query.Get("phase"); p != "" {
+		phase, err := strconv.Atoi(p)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid phase", err)
+			return
+		}
+		filter.Phase = phase
+	}
+
+	writeJSONResponse(w, http.StatusOK, s.cases.List(filter))
+}
+
+// GET /api/cases/{phase}/{id}           - Get a case's config
+// GET /api/cases/{phase}/{id}/snapshots - Get a case's snapshots (generated on demand)
+// GET /api/cases/{phase}/{id}/race      - Get a comparison case's race (generated on demand)
+// GET /api/cases/{phase}/{id}/export    - See handleCaseExport
+// GET /api/cases/{phase}/{id}/insights  - See handleCaseInsights
+func (s *Server) handleCase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/cases/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "snapshots" && parts[2] != "race" && parts[2] != "export" && parts[2] != "insights") {
+		writeError(w, http.StatusNotFound, "Not found", nil)
+		return
+	}
+	phase, err := strconv.Atoi(parts[0])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid phase", err)
+		return
+	}
+	id := parts[1]
+
+	if len(parts) == 3 && parts[2] == "export" {
+		s.handleCaseExport(w, r, phase, id)
+		return
+	}
+	if len(parts) == 3 && parts[2] == "insights" {
+		s.handleCaseInsights(w, r, phase, id)
+		return
+	}
+
+	if len(parts) == 2 {
+		config, err := s.cases.Config(phase, id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Invalid case", err)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, config)
+		return
+	}
+
+	get := s.cases.Snapshots
+	if parts[2] == "race" {
+		get = s.cases.Race
+	}
+	data, key, err := get(phase, id)
+	if errors.Is(err, ErrCaseNotFound) {
+		writeError(w, http.StatusNotFound, "Invalid case", err)
+		return
+	}
+	if errors.Is(err, ErrNoRace) {
+		writeError(w, http.StatusNotFound, "Not a comparison case", err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate case", err)
+		return
+	}
+
+	// Case snapshots and races are fully determined by the case definition
+	etag := resultETag(key)
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
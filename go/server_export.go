@@ -0,0 +1,90 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// handleRunExport handles GET /api/runs/{id}/export?table=steps|points&format=csv|columnar
+func (s *Server) handleRunExport(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	run, ok := s.runs.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Run not found: "+id, nil)
+		return
+	}
+	writeExport(w, r, "run-"+run.ID, run.Snapshots)
+}
+
+// handleCaseExport handles GET /api/cases/{phase}/{id}/export?table=steps|points&format=csv|columnar
+func (s *Server) handleCaseExport(w http.ResponseWriter, r *http.Request, phase int, id string) {
+	snapshots, ok := s.caseSnapshots(w, phase, id)
+	if !ok {
+		return
+	}
+	writeExport(w, r, fmt.Sprintf("phase%d-%s", phase, id), snapshots)
+}
+
+// caseSnapshots returns the decoded snapshots of a case, generating them on
+// demand. On failure it writes the error response and returns false.
+func (s *Server) caseSnapshots(w http.ResponseWriter, phase int, id string) (interface{}, bool) {
+	data, _, err := s.cases.Snapshots(phase, id)
+	if errors.Is(err, ErrCaseNotFound) {
+		writeError(w, http.StatusNotFound, "Invalid case", err)
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to generate case", err)
+		return nil, false
+	}
+
+	snapshots, err := DecodeSnapshots(phase, data)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to read case snapshots", err)
+		return nil, false
+	}
+	return snapshots, true
+}
+
+// writeExport flattens snapshots and sends the requested table as a download
+func writeExport(w http.ResponseWriter, r *http.Request, name string, snapshots interface{}) {
+	tableName := r.URL.Query().Get("table")
+	if tableName == "" {
+		tableName = TableSteps
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatColumnar {
+		writeError(w, http.StatusBadRequest, "Invalid format", fmt.Errorf("want %s or %s, got %q", FormatCSV, FormatColumnar, format))
+		return
+	}
+
+	tables, err := FlattenSnapshots(snapshots)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to export snapshots", err)
+		return
+	}
+	t, err := tables.Table(tableName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid table", err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == FormatColumnar {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s%s"`, name, t.Name, ExportFileExt(format)))
+	if err := WriteTable(w, t, format); err != nil {
+		// Headers are already sent; the client sees a truncated body
+		return
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+)
+
+// Phase2FeaturesRandomRequest combines N-feature data generation, training and projection configuration
+type Phase2FeaturesRandomRequest struct {
+	DataConfig     linear.DataGenConfigN   `json:"data_config"`
+	TrainingConfig linear.TrainingConfigN  `json:"training_config"`
+	Projection     linear.ProjectionConfig `json:"projection"`
+	LossGridConfig *linear.LossGridConfig  `json:"loss_grid_config,omitempty"` // defaults to a grid around the projected trajectory
+	Contours       *linear.ContourConfig   `json:"contours,omitempty"`         // adds iso-contours of the loss grid
+}
+
+// Phase2FeaturesTrainingRequest combines a custom N-feature dataset with training and projection configuration
+type Phase2FeaturesTrainingRequest struct {
+	Data           []linear.DataPointN     `json:"data"`
+	Config         linear.TrainingConfigN  `json:"config"`
+	Projection     linear.ProjectionConfig `json:"projection"`
+	LossGridConfig *linear.LossGridConfig  `json:"loss_grid_config,omitempty"` // defaults to a grid around the projected trajectory
+	Contours       *linear.ContourConfig   `json:"contours,omitempty"`         // adds iso-contours of the loss grid
+}
+
+// POST /api/phase2/features/random - Generate random N-feature data and train
+func (s *Server) handlePhase2FeaturesRandom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req Phase2FeaturesRandomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Check limits before allocating anything
+	if err := s.config.Limits.CheckTraining(req.DataConfig.NumPoints, req.TrainingConfig.MaxSteps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := s.config.Limits.CheckFeatures(len(req.DataConfig.TrueW)); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := linear.ValidateDataGenConfigN(req.DataConfig); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid data config", err)
+		return
+	}
+
+	data := linear.GenerateRandomDataN(req.DataConfig)
+	s.trainPhase2Features(w, r, cache.KeyInput{DataConfig: req.DataConfig}, data, req.TrainingConfig, req.Projection, req.LossGridConfig, req.Contours)
+}
+
+// POST /api/phase2/features/custom - Train with custom N-feature data
+func (s *Server) handlePhase2FeaturesCustom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req Phase2FeaturesTrainingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	s.trainPhase2Features(w, r, cache.KeyInput{Dataset: req.Data}, req.Data, req.Config, req.Projection, req.LossGridConfig, req.Contours)
+}
+
+// phase2FeaturesResult is the cached part of an N-feature Phase 2 run
+type phase2FeaturesResult struct {
+	Features   linear.FeatureRun        `json:"features"`
+	Slice      []linear.DataPoint2D     `json:"slice"`
+	LossGrid   linear.LossGrid          `json:"loss_grid"`
+	Contours   []linear.Contour         `json:"contours,omitempty"`
+	Hessian    linear.HessianAnalysis   `json:"hessian"`
+	Trajectory linear.TrajectoryMetrics `json:"trajectory"`
+	Snapshots  []linear.LinearSnapshot  `json:"snapshots"`
+}
+
+// trainPhase2Features validates the dataset, trains, projects the run onto a
+// plane and stores it as a Phase 2 run. The run's dataset, loss grid, Hessian,
+// trajectory and snapshots describe the plane; Features holds the full run.
+func (s *Server) trainPhase2Features(w http.ResponseWriter, r *http.Request, keyInput cache.KeyInput, data []linear.DataPointN, config linear.TrainingConfigN, projection linear.ProjectionConfig, gridConfig *linear.LossGridConfig, contours *linear.ContourConfig) {
+	// Validate dataset
+	if err := s.config.Limits.CheckTraining(len(data), config.MaxSteps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := linear.ValidateDatasetN(data); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+	if err := s.config.Limits.CheckFeatures(len(data[0].X)); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	// Validate loss grid; without bounds, they are fitted to the projected trajectory
+	grid := linear.LossGridConfig{Resolution: linear.DefaultLossGridConfig().Resolution}
+	if gridConfig != nil {
+		grid = *gridConfig
+	}
+	if err := linear.ValidateLossGridConfig(grid); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid loss grid config", err)
+		return
+	}
+	if err := s.config.Limits.CheckGridResolution(grid.Resolution); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid loss grid config", err)
+		return
+	}
+	if contours != nil {
+		if err := linear.ValidateContourConfig(*contours); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid contour config", err)
+			return
+		}
+	}
+
+	// Run training, project it and compute the slice's loss surface (or reuse an identical result)
+	keyInput.Phase = "phase2-features"
+	keyInput.TrainingConfig = config
+	keyInput.Extra = map[string]interface{}{"projection": projection, "loss_grid": gridConfig, "contours": contours}
+	key := resultKey(keyInput)
+	var result phase2FeaturesResult
+	err := s.trainCached(w, key, &result, func() error {
+		snapshots, err := linear.RunTrainingNContext(r.Context(), data, config)
+		if err != nil {
+			return err
+		}
+		plane, err := linear.ResolveProjection(projection, data, config.Bias, snapshots)
+		if err != nil {
+			return err
+		}
+		optimum, _ := linear.OptimumN(data, config.Bias)
+		result.Features = linear.FeatureRun{
+			NumFeatures: len(data[0].X),
+			Bias:        config.Bias,
+			Dataset:     data,
+			Optimum:     optimum,
+			Hessian:     linear.AnalyzeHessianN(data, config.Bias, config.LR),
+			Projection:  plane,
+			Snapshots:   snapshots,
+		}
+		result.Slice = plane.SliceDataset(data, config.Bias)
+		result.Snapshots = plane.Snapshots(data, config.Bias, config.LR, snapshots)
+		if grid.AutoBounds() {
+			fitted := linear.FitLossGridConfig(result.Slice, grid.Resolution, result.Snapshots)
+			fitted.Refine = grid.Refine
+			grid = fitted
+		}
+		result.LossGrid = linear.BuildLossGrid(result.Slice, grid)
+		result.Contours = contourLossGrid(&result.LossGrid, contours)
+		result.Hessian = linear.AnalyzeHessian(result.Slice, config.LR)
+		result.Trajectory = linear.SummarizeTrajectory(result.Snapshots, result.Slice)
+		return nil
+	})
+	if err != nil && r.Context().Err() != nil {
+		writeError(w, http.StatusServiceUnavailable, "Training stopped", err)
+		return
+	}
+	if err != nil {
+		// A w_init or projection that does not fit the data
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	run := s.runs.Add(Run{
+		Phase:      "phase2",
+		NumSteps:   len(result.Snapshots),
+		Dataset:    result.Slice,
+		LossGrid:   &result.LossGrid,
+		Contours:   result.Contours,
+		Hessian:    &result.Hessian,
+		Trajectory: &result.Trajectory,
+		Features:   &result.Features,
+		ResultKey:  key,
+		Snapshots:  result.Snapshots,
+	})
+
+	writeJSONResponse(w, http.StatusCreated, run)
+}
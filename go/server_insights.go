@@ -0,0 +1,39 @@
+package core
+
+import (
+	"net/http"
+)
+
+// handleRunInsights handles GET /api/runs/{id}/insights
+func (s *Server) handleRunInsights(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	run, ok := s.runs.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Run not found: "+id, nil)
+		return
+	}
+	writeInsights(w, run.Snapshots)
+}
+
+// handleCaseInsights handles GET /api/cases/{phase}/{id}/insights
+func (s *Server) handleCaseInsights(w http.ResponseWriter, r *http.Request, phase int, id string) {
+	snapshots, ok := s.caseSnapshots(w, phase, id)
+	if !ok {
+		return
+	}
+	writeInsights(w, snapshots)
+}
+
+// writeInsights analyzes snapshots and sends the observations
+func writeInsights(w http.ResponseWriter, snapshots interface{}) {
+	observations, err := AnalyzeSnapshots(snapshots)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to analyze snapshots", err)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, observations)
+}
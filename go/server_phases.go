@@ -0,0 +1,308 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iOliverNguyen/ml-viz/go/cache"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/minibatch"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+)
+
+// Phase2RandomDataRequest combines 2D data generation, training and loss grid configuration
+type Phase2RandomDataRequest struct {
+	DataConfig     linear.DataGenConfig2D      `json:"data_config"`
+	TrainingConfig linear.TrainingConfig2D     `json:"training_config"`
+	LossGridConfig *linear.LossGridConfig      `json:"loss_grid_config,omitempty"` // defaults to a grid fitted to the trajectory
+	Contours       *linear.ContourConfig       `json:"contours,omitempty"`         // adds iso-contours of the loss grid
+	GradientField  *linear.GradientFieldConfig `json:"gradient_field,omitempty"`   // adds the gradient field over the loss grid's box
+}
+
+// Phase2TrainingRequest combines a custom 2D dataset with training and loss grid configuration
+type Phase2TrainingRequest struct {
+	Data           []linear.DataPoint2D        `json:"data"`
+	Config         linear.TrainingConfig2D     `json:"config"`
+	LossGridConfig *linear.LossGridConfig      `json:"loss_grid_config,omitempty"` // defaults to a grid fitted to the trajectory
+	Contours       *linear.ContourConfig       `json:"contours,omitempty"`         // adds iso-contours of the loss grid
+	GradientField  *linear.GradientFieldConfig `json:"gradient_field,omitempty"`   // adds the gradient field over the loss grid's box
+}
+
+// Phase3RandomDataRequest combines neuron data generation, initialization and training configuration
+type Phase3RandomDataRequest struct {
+	DataConfig     neuron.DataGenConfig  `json:"data_config"`
+	InitParams     neuron.NeuronParams   `json:"init_params"`
+	TrainingConfig neuron.TrainingConfig `json:"training_config"`
+}
+
+// Phase3TrainingRequest combines a custom neuron dataset with initialization and training configuration
+type Phase3TrainingRequest struct {
+	Data       []neuron.DataPoint2DNeuron `json:"data"`
+	InitParams neuron.NeuronParams        `json:"init_params"`
+	Config     neuron.TrainingConfig      `json:"config"`
+}
+
+// POST /api/phase2/dataset/random - Generate random 2D data and train
+func (s *Server) handlePhase2Random(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req Phase2RandomDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Check limits before allocating anything
+	if err := s.config.Limits.CheckTraining(req.DataConfig.NumPoints, req.TrainingConfig.MaxSteps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	if err := req.DataConfig.Correlated.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid data config", err)
+		return
+	}
+	data := linear.GenerateRandomData(req.DataConfig)
+	s.trainPhase2(w, r, cache.KeyInput{DataConfig: req.DataConfig}, data, req.TrainingConfig, req.LossGridConfig, req.Contours, req.GradientField)
+}
+
+// POST /api/phase2/dataset/custom - Train with custom 2D data
+func (s *Server) handlePhase2Custom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req Phase2TrainingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	s.trainPhase2(w, r, cache.KeyInput{Dataset: req.Data}, req.Data, req.Config, req.LossGridConfig, req.Contours, req.GradientField)
+}
+
+// phase2Result is the cached part of a Phase 2 run
+type phase2Result struct {
+	LossGrid      linear.LossGrid          `json:"loss_grid"`
+	Contours      []linear.Contour         `json:"contours,omitempty"`
+	GradientField *linear.GradientField    `json:"gradient_field,omitempty"`
+	DatasetStats  linear.DatasetStats2D    `json:"dataset_stats"`
+	Hessian       linear.HessianAnalysis   `json:"hessian"`
+	Trajectory    linear.TrajectoryMetrics `json:"trajectory"`
+	Snapshots     []linear.LinearSnapshot  `json:"snapshots"`
+}
+
+// trainPhase2 validates the dataset, trains, computes the loss grid (and its
+// contours and gradient field when asked) and stores the run.
+// keyInput identifies the data (dataset or data config) for the result cache.
+func (s *Server) trainPhase2(w http.ResponseWriter, r *http.Request, keyInput cache.KeyInput, data []linear.DataPoint2D, config linear.TrainingConfig2D, gridConfig *linear.LossGridConfig, contours *linear.ContourConfig, field *linear.GradientFieldConfig) {
+	// Validate dataset
+	if err := s.config.Limits.CheckTraining(len(data), config.MaxSteps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := linear.ValidateDataset(data); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+	if err := linear.ValidateTrainingConfig2D(config); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+
+	// Validate loss grid; without bounds, they are fitted to the trajectory
+	grid := linear.LossGridConfig{Resolution: linear.DefaultLossGridConfig().Resolution}
+	if gridConfig != nil {
+		grid = *gridConfig
+	}
+	if err := linear.ValidateLossGridConfig(grid); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid loss grid config", err)
+		return
+	}
+	if err := s.config.Limits.CheckGridResolution(grid.Resolution); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid loss grid config", err)
+		return
+	}
+	if contours != nil {
+		if err := linear.ValidateContourConfig(*contours); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid contour config", err)
+			return
+		}
+	}
+	if field != nil {
+		if err := linear.ValidateGradientFieldConfig(*field); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid gradient field config", err)
+			return
+		}
+	}
+
+	// Run training and compute the loss surface (or reuse an identical result)
+	keyInput.Phase = "phase2"
+	keyInput.TrainingConfig = config
+	keyInput.Extra = map[string]interface{}{"loss_grid": grid, "contours": contours, "gradient_field": field}
+	key := resultKey(keyInput)
+	var result phase2Result
+	err := s.trainCached(w, key, &result, func() (err error) {
+		result.Snapshots, err = linear.RunTrainingContext(r.Context(), data, config)
+		if err != nil {
+			return err
+		}
+		result.Hessian = linear.AnalyzeHessian(data, config.LR)
+		result.DatasetStats = linear.SummarizeDataset2D(data)
+		result.Trajectory = linear.SummarizeTrajectory(result.Snapshots, data)
+		if grid.AutoBounds() {
+			fitted := linear.FitLossGridConfig(data, grid.Resolution, result.Snapshots)
+			fitted.Refine = grid.Refine
+			grid = fitted
+		}
+		result.LossGrid = linear.BuildLossGrid(data, grid)
+		if field != nil {
+			f := linear.ComputeGradientField(data, grid.W1Min, grid.W1Max, grid.W2Min, grid.W2Max, *field)
+			result.GradientField = &f
+		}
+		result.Contours = contourLossGrid(&result.LossGrid, contours)
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "Training stopped", err)
+		return
+	}
+
+	run := s.runs.Add(Run{
+		Phase:         "phase2",
+		NumSteps:      len(result.Snapshots),
+		Dataset:       data,
+		LossGrid:      &result.LossGrid,
+		Contours:      result.Contours,
+		GradientField: result.GradientField,
+		DatasetStats:  &result.DatasetStats,
+		Hessian:       &result.Hessian,
+		Trajectory:    &result.Trajectory,
+		ResultKey:     key,
+		Snapshots:     result.Snapshots,
+	})
+
+	writeJSONResponse(w, http.StatusCreated, run)
+}
+
+// POST /api/phase3/dataset/random - Generate random data and train a single neuron
+func (s *Server) handlePhase3Random(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req Phase3RandomDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// Check limits before allocating anything
+	if err := s.config.Limits.CheckTraining(req.DataConfig.NumPoints, req.TrainingConfig.NumSteps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	// Generate random data
+	data, err := neuron.GenerateDataset(req.DataConfig)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to generate data", err)
+		return
+	}
+
+	s.trainPhase3(w, r, cache.KeyInput{DataConfig: req.DataConfig}, data, req.InitParams, req.TrainingConfig)
+}
+
+// POST /api/phase3/dataset/custom - Train a single neuron with custom data
+func (s *Server) handlePhase3Custom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+
+	var req Phase3TrainingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	s.trainPhase3(w, r, cache.KeyInput{Dataset: req.Data}, req.Data, req.InitParams, req.Config)
+}
+
+// trainPhase3 validates the dataset and activation, trains the neuron and stores the run.
+// keyInput identifies the data (dataset or data config) for the result cache.
+func (s *Server) trainPhase3(w http.ResponseWriter, r *http.Request, keyInput cache.KeyInput, data []neuron.DataPoint2DNeuron, initParams neuron.NeuronParams, config neuron.TrainingConfig) {
+	// Default to sigmoid like the case generators do
+	if config.Activation == "" {
+		config.Activation = "sigmoid"
+	}
+	if err := neuron.ValidateActivation(config); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+	if err := config.LineSearch.Validate(false); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+	if err := minibatch.Validate(config.BatchSize, config.LineSearch.Active()); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid training config", err)
+		return
+	}
+
+	// Zero-initialize weights when none are given
+	if initParams.W == nil {
+		initParams.W = make([]float64, 2)
+	}
+
+	// Validate dataset
+	if err := s.config.Limits.CheckTraining(len(data), config.NumSteps); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	if err := neuron.ValidateDataset(data, len(initParams.W)); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid dataset", err)
+		return
+	}
+
+	// Run training (or reuse an identical result) and store it as a new run
+	keyInput.Phase = "phase3"
+	keyInput.TrainingConfig = config
+	keyInput.Extra = initParams
+	key := resultKey(keyInput)
+	var snapshots []neuron.NeuronSnapshot
+	err := s.trainCached(w, key, &snapshots, func() (err error) {
+		snapshots, err = neuron.TrainContext(r.Context(), data, initParams, config)
+		return err
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, "Training stopped", err)
+		return
+	}
+	run := s.runs.Add(Run{
+		Phase:     "phase3",
+		NumSteps:  len(snapshots),
+		Dataset:   data,
+		ResultKey: key,
+		Snapshots: snapshots,
+	})
+
+	writeJSONResponse(w, http.StatusCreated, run)
+}
+
+// contourLossGrid extracts the contours a config asks for, dropping the grid
+// points when only the contours are wanted. A nil config extracts nothing.
+func contourLossGrid(grid *linear.LossGrid, config *linear.ContourConfig) []linear.Contour {
+	if config == nil {
+		return nil
+	}
+	contours := linear.ExtractContours(*grid, *config)
+	if config.OmitGrid {
+		grid.Points = []linear.LossGridPoint{}
+	}
+	return contours
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"github.com/iOliverNguyen/ml-viz/go/linesearch"
 )
 
 // PointSnapshot captures per-point breakdown for pedagogical inspection
@@ -18,7 +20,7 @@ type PointSnapshot struct {
 // UpdateDetails captures parameter update breakdown for pedagogy
 type UpdateDetails struct {
 	WOld   float64 `json:"w_old"`
-	LR     float64 `json:"lr"`
+	LR     float64 `json:"lr"` // the step length accepted by the line search, if any
 	GradW  float64 `json:"grad_w"`
 	DeltaW float64 `json:"delta_w"` // -lr * grad_w
 	WNew   float64 `json:"w_new"`
@@ -28,7 +30,7 @@ type UpdateDetails struct {
 type Snapshot struct {
 	Step  int     `json:"step"`
 	W     float64 `json:"w"`
-	GradW float64 `json:"grad_w"`
+	GradW float64 `json:"grad_w"` // over the mini-batch when Batch is set
 	Loss  float64 `json:"loss"`
 
 	// NEW: Per-point details for inspection
@@ -36,6 +38,12 @@ type Snapshot struct {
 
 	// NEW: Update breakdown for pedagogy
 	UpdateComponents UpdateDetails `json:"update_components"`
+
+	// Trial steps of the line search; omitted when stepping by the learning rate
+	LineSearch *linesearch.Result `json:"line_search,omitempty"`
+
+	// Points of the mini-batch behind GradW; omitted for full batches
+	Batch []int `json:"batch,omitempty"`
 }
 
 // WriteSnapshots marshals snapshots to JSON and writes to file
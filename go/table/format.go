@@ -0,0 +1,202 @@
+package table
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Columnar file layout (all integers little-endian):
+//
+//	magic    8 bytes  "MLVCOL1\n"
+//	hlen     uint32   length of the JSON header
+//	header   hlen     {"name": ..., "num_rows": N, "columns": [{"name": ..., "type": ...}, ...]}
+//	columns  one block per column, in header order:
+//	           float64, int64  N × 8 bytes
+//	           bool            N × 1 byte (0 or 1)
+//	           string          N × (uint32 length + UTF-8 bytes)
+//
+// Numeric blocks can be mapped directly, e.g. numpy.frombuffer(buf, "<f8", N, offset).
+const columnarMagic = "MLVCOL1\n"
+
+// Limits on what a columnar file may declare, so a truncated or hostile file
+// fails instead of allocating gigabytes
+const (
+	maxColumnarHeader = 1 << 20 // bytes of JSON header
+	maxColumnarRows   = 1 << 26
+	maxColumnarString = 1 << 20 // bytes of one string value
+
+	// columnarChunk is the most rows allocated ahead of reading them, so memory
+	// grows with the data actually present rather than with num_rows
+	columnarChunk = 1 << 16
+)
+
+// columnarHeader is the JSON header of a columnar file
+type columnarHeader struct {
+	Name    string    `json:"name"`
+	NumRows int       `json:"num_rows"`
+	Columns []*Column `json:"columns"`
+}
+
+// WriteCSV writes the table as CSV with a header row
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(t.Columns))
+	for row := 0; row < t.NumRows(); row++ {
+		for i, c := range t.Columns {
+			record[i] = c.Format(row)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteColumnar writes the table in the columnar binary layout described above
+func (t *Table) WriteColumnar(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	header, err := json.Marshal(columnarHeader{Name: t.Name, NumRows: t.NumRows(), Columns: t.Columns})
+	if err != nil {
+		return err
+	}
+	bw.WriteString(columnarMagic)
+	binary.Write(bw, binary.LittleEndian, uint32(len(header)))
+	bw.Write(header)
+
+	var buf [8]byte
+	for _, c := range t.Columns {
+		switch c.Type {
+		case Float64:
+			for _, v := range c.Floats {
+				binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+				bw.Write(buf[:])
+			}
+		case Int64:
+			for _, v := range c.Ints {
+				binary.LittleEndian.PutUint64(buf[:], uint64(v))
+				bw.Write(buf[:])
+			}
+		case Bool:
+			for _, v := range c.Bools {
+				if v {
+					bw.WriteByte(1)
+				} else {
+					bw.WriteByte(0)
+				}
+			}
+		case String:
+			for _, v := range c.Strings {
+				binary.LittleEndian.PutUint32(buf[:4], uint32(len(v)))
+				bw.Write(buf[:4])
+				bw.WriteString(v)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadColumnar reads a table written by WriteColumnar
+func ReadColumnar(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(columnarMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != columnarMagic {
+		return nil, fmt.Errorf("not a columnar table file")
+	}
+
+	var hlen uint32
+	if err := binary.Read(br, binary.LittleEndian, &hlen); err != nil {
+		return nil, err
+	}
+	if hlen > maxColumnarHeader {
+		return nil, fmt.Errorf("columnar header of %d bytes exceeds the limit of %d", hlen, maxColumnarHeader)
+	}
+	headerData := make([]byte, hlen)
+	if _, err := io.ReadFull(br, headerData); err != nil {
+		return nil, err
+	}
+	var header columnarHeader
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return nil, fmt.Errorf("invalid columnar header: %w", err)
+	}
+
+	n := header.NumRows
+	if n < 0 || n > maxColumnarRows {
+		return nil, fmt.Errorf("columnar num_rows %d is outside [0, %d]", n, maxColumnarRows)
+	}
+	prealloc := min(n, columnarChunk)
+	var buf [8]byte
+	for _, c := range header.Columns {
+		if c == nil {
+			return nil, fmt.Errorf("columnar header has a null column")
+		}
+		switch c.Type {
+		case Float64:
+			c.Floats = make([]float64, 0, prealloc)
+			for i := 0; i < n; i++ {
+				if _, err := io.ReadFull(br, buf[:]); err != nil {
+					return nil, err
+				}
+				c.Floats = append(c.Floats, math.Float64frombits(binary.LittleEndian.Uint64(buf[:])))
+			}
+		case Int64:
+			c.Ints = make([]int64, 0, prealloc)
+			for i := 0; i < n; i++ {
+				if _, err := io.ReadFull(br, buf[:]); err != nil {
+					return nil, err
+				}
+				c.Ints = append(c.Ints, int64(binary.LittleEndian.Uint64(buf[:])))
+			}
+		case Bool:
+			c.Bools = make([]bool, 0, prealloc)
+			for i := 0; i < n; i++ {
+				b, err := br.ReadByte()
+				if err != nil {
+					return nil, err
+				}
+				c.Bools = append(c.Bools, b != 0)
+			}
+		case String:
+			c.Strings = make([]string, 0, prealloc)
+			for i := 0; i < n; i++ {
+				if _, err := io.ReadFull(br, buf[:4]); err != nil {
+					return nil, err
+				}
+				size := binary.LittleEndian.Uint32(buf[:4])
+				if size > maxColumnarString {
+					return nil, fmt.Errorf("column %s has a string of %d bytes, over the limit of %d", c.Name, size, maxColumnarString)
+				}
+				s := make([]byte, size)
+				if _, err := io.ReadFull(br, s); err != nil {
+					return nil, err
+				}
+				c.Strings = append(c.Strings, string(s))
+			}
+		default:
+			return nil, fmt.Errorf("column %s has unknown type %q", c.Name, c.Type)
+		}
+	}
+
+	return &Table{Name: header.Name, Columns: header.Columns}, nil
+}
@@ -0,0 +1,142 @@
+// Package table holds tidy, typed tables and writes them as CSV or as a simple
+// columnar binary file that can be read without any third-party library.
+package table
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ColumnType is the element type of a column
+type ColumnType string
+
+const (
+	Float64 ColumnType = "float64"
+	Int64   ColumnType = "int64"
+	Bool    ColumnType = "bool"
+	String  ColumnType = "string"
+)
+
+// Column is one named, typed column. Only the slice matching Type is used.
+type Column struct {
+	Name    string     `json:"name"`
+	Type    ColumnType `json:"type"`
+	Floats  []float64  `json:"-"`
+	Ints    []int64    `json:"-"`
+	Bools   []bool     `json:"-"`
+	Strings []string   `json:"-"`
+}
+
+// Len returns the number of values in the column
+func (c *Column) Len() int {
+	switch c.Type {
+	case Float64:
+		return len(c.Floats)
+	case Int64:
+		return len(c.Ints)
+	case Bool:
+		return len(c.Bools)
+	default:
+		return len(c.Strings)
+	}
+}
+
+// Format returns row i as text, as written to CSV
+func (c *Column) Format(i int) string {
+	switch c.Type {
+	case Float64:
+		v := c.Floats[i]
+		if math.IsNaN(v) {
+			return "NaN"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case Int64:
+		return strconv.FormatInt(c.Ints[i], 10)
+	case Bool:
+		return strconv.FormatBool(c.Bools[i])
+	default:
+		return c.Strings[i]
+	}
+}
+
+// Table is a named list of equally long columns
+type Table struct {
+	Name    string
+	Columns []*Column
+}
+
+// New creates a table with the given schema
+func New(name string, columns ...*Column) *Table {
+	return &Table{Name: name, Columns: columns}
+}
+
+// Float declares a float64 column
+func Float(name string) *Column { return &Column{Name: name, Type: Float64} }
+
+// Int declares an int64 column
+func Int(name string) *Column { return &Column{Name: name, Type: Int64} }
+
+// BoolCol declares a bool column
+func BoolCol(name string) *Column { return &Column{Name: name, Type: Bool} }
+
+// Str declares a string column
+func Str(name string) *Column { return &Column{Name: name, Type: String} }
+
+// NumRows returns the number of rows in the table
+func (t *Table) NumRows() int {
+	if len(t.Columns) == 0 {
+		return 0
+	}
+	return t.Columns[0].Len()
+}
+
+// Append adds one row. Values must match the schema in order and type
+// (int, int64, float64, bool or string).
+func (t *Table) Append(values ...interface{}) error {
+	if len(values) != len(t.Columns) {
+		return fmt.Errorf("table %s: got %d values for %d columns", t.Name, len(values), len(t.Columns))
+	}
+
+	for i, v := range values {
+		c := t.Columns[i]
+		ok := true
+		switch c.Type {
+		case Float64:
+			var f float64
+			f, ok = v.(float64)
+			c.Floats = append(c.Floats, f)
+		case Int64:
+			switch n := v.(type) {
+			case int:
+				c.Ints = append(c.Ints, int64(n))
+			case int64:
+				c.Ints = append(c.Ints, n)
+			default:
+				ok = false
+			}
+		case Bool:
+			var b bool
+			b, ok = v.(bool)
+			c.Bools = append(c.Bools, b)
+		case String:
+			var s string
+			s, ok = v.(string)
+			c.Strings = append(c.Strings, s)
+		}
+		if !ok {
+			return fmt.Errorf("table %s: column %s expects %s, got %T", t.Name, c.Name, c.Type, v)
+		}
+	}
+	return nil
+}
+
+// Column returns the column with the given name, or nil
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
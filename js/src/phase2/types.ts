@@ -1,6 +1,8 @@
 // Phase 2: Linear Regression with 2 Parameters (w1, w2)
 // Type definitions matching Go backend JSON structures (snake_case)
 
+import type { LineSearchResult } from '../types';
+
 export interface DataPoint2D {
   x1: number;
   x2: number;
@@ -40,6 +42,27 @@ export interface LinearSnapshot {
   gradient_direction: number; // radians [-π, π]
   point_details: PointSnapshot2D[];
   update_components: UpdateDetails2D;
+  // Curvature: the Hessian (Gauss-Newton matrix for gauss-newton), the Newton
+  // direction -H⁻¹∇L, and the BFGS inverse-Hessian estimate
+  hessian: [[number, number], [number, number]];
+  newton_direction: [number, number];
+  inverse_hessian?: [[number, number], [number, number]]; // bfgs only
+  // The update is step_length·search_direction (lr and -∇L for gradient descent)
+  search_direction: [number, number];
+  step_length: number;
+  conjugacy_beta: number; // β of cg; 0 on restarts
+  coordinate?: number; // coordinate only: 1 when w1 moved, 2 when w2 moved
+  line_search?: LineSearchResult; // trial steps of the line search; omitted without one
+  // Trajectory metrics, filled by annotateTrajectory (angles in radians)
+  gradient_turn_angle: number; // angle between this and the previous gradient
+  step_optimum_angle: number; // angle between the update and the direction to the optimum
+  path_efficiency: number; // straight-line distance from the start over path length so far
+  sign_flips_w1: number; // times grad_w1 has changed sign so far
+  sign_flips_w2: number; // times grad_w2 has changed sign so far
+
+  // Parameter error w - w* along the Hessian's eigenvectors, filled by annotateEigenErrors
+  error_eigen1: number; // along the steepest direction
+  error_eigen2: number; // along the flattest direction
 }
 
 export interface LossGridPoint {
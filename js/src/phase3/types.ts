@@ -1,5 +1,7 @@
 // Phase 3 TypeScript interfaces for Single Neuron with Activation Functions
 
+import type { LineSearchConfig, LineSearchResult } from '../types';
+
 export interface NeuronParams {
   w: number[];  // weights [w1, w2]
   b: number;    // bias
@@ -28,7 +30,7 @@ export interface PointSnapshotNeuron {
   dz_dw: number[];       // [∂z/∂w1, ∂z/∂w2] = [x1, x2]
   dL_dw: number[];       // [∂L/∂w1, ∂L/∂w2]
   dL_db: number;         // ∂L/∂b
-  in_saturation: boolean; // whether σ'(z) < 0.01
+  in_saturation: boolean; // whether z is in the activation's saturation zone
 }
 
 export interface UpdateDetailsNeuron {
@@ -51,6 +53,10 @@ export interface ChainRuleBreakdown {
   components: ChainRuleComponent[];
 }
 
+export type Activation =
+  | "sigmoid" | "relu" | "tanh" | "leaky_relu" | "elu"
+  | "gelu" | "softplus" | "swish" | "linear" | "hard_sigmoid";
+
 export interface NeuronSnapshot {
   step: number;
   params: NeuronParams;
@@ -60,25 +66,29 @@ export interface NeuronSnapshot {
   dL_dz: number;                // avg ∂L/∂z
   dL_da: number;                // avg ∂L/∂a
   local_derivative: number;     // avg σ'(z)
-  activation: "sigmoid" | "relu" | "tanh";
-  in_saturation_zone: boolean;  // whether avg |σ'(z)| < 0.01
+  activation: Activation;
+  alpha?: number;               // α of leaky_relu or elu
+  in_saturation_zone: boolean;  // whether avg z is in the activation's saturation zone
   loss: number;                 // avg loss across dataset
   point_details: PointSnapshotNeuron[];
   update_components: UpdateDetailsNeuron;
   chain_rule_breakdown: ChainRuleBreakdown;
+  line_search?: LineSearchResult; // omitted when stepping by the learning rate
 }
 
 export interface TrainingConfig {
   learning_rate: number;
   num_steps: number;
   activation: string;
+  alpha?: number;             // leaky_relu slope (default 0.01) or elu scale (default 1)
+  line_search?: LineSearchConfig; // armijo or wolfe
 }
 
 export interface NeuronTrainingCase {
   case_id: string;
   description: string;
   category: string;           // "saturation", "optimal", "dying-relu", "comparison"
-  activation: string;         // "sigmoid", "relu", "tanh"
+  activation: string;         // one of Activation
   dataset: DataPoint2DNeuron[];
   init_params: NeuronParams;
   final_params: NeuronParams;
@@ -44,16 +44,70 @@ export interface LinearSnapshot {
   gradient_direction: number;
   point_details: PointSnapshot2D[];
   update_components: UpdateDetails2D;
+  // Curvature: the Hessian (Gauss-Newton matrix for gauss-newton), the Newton
+  // direction -H⁻¹∇L (zero when H is singular), and the BFGS inverse-Hessian estimate
+  hessian: Matrix2;
+  newton_direction: [number, number];
+  inverse_hessian?: Matrix2; // bfgs only
+  // The update is step_length·search_direction (lr and -∇L for gradient descent)
+  search_direction: [number, number];
+  step_length: number;
+  conjugacy_beta: number; // β of cg; 0 on restarts
+  coordinate?: number; // coordinate only: 1 when w1 moved, 2 when w2 moved
+  line_search?: LineSearchResult; // trial steps of the line search; omitted without one
+  // Trajectory metrics, filled by annotateTrajectory (angles in radians)
+  gradient_turn_angle: number; // angle between this and the previous gradient
+  step_optimum_angle: number; // angle between the update and the direction to the optimum
+  path_efficiency: number; // straight-line distance from the start over path length so far
+  sign_flips_w1: number; // times grad_w1 has changed sign so far
+  sign_flips_w2: number; // times grad_w2 has changed sign so far
+
+  // Parameter error w - w* along the Hessian's eigenvectors, filled by annotateEigenErrors
+  error_eigen1: number; // along the steepest direction
+  error_eigen2: number; // along the flattest direction
 }
 
+export type Matrix2 = [[number, number], [number, number]];
+
+export type Optimizer2D = 'gd' | 'newton' | 'damped-newton' | 'gauss-newton' | 'bfgs' | 'cg' | 'coordinate';
+
 export interface TrainingConfig2D {
   w1_init?: number; // Initial weight 1 (default: 0)
   w2_init?: number; // Initial weight 2 (default: 0)
   lr: number;
   max_steps: number;
+  optimizer?: Optimizer2D; // default: gd
+  damping?: number; // μ of damped-newton
+  line_search?: LineSearchConfig; // picks each step length, starting from the optimizer's own step
   data: DataPoint2D[];
 }
 
+export interface LineSearchConfig {
+  method: 'fixed' | 'armijo' | 'wolfe' | 'exact';
+  c1?: number; // sufficient decrease constant (default 1e-4)
+  c2?: number; // curvature constant of wolfe (default 0.9)
+  shrink?: number; // backtracking factor of armijo (default 0.5)
+  max_trials?: number; // default 30
+}
+
+export interface LineSearchTrial {
+  step: number; // α
+  loss: number; // L(θ + αd)
+  bound: number; // L(θ) + c1·α·slope, the largest loss with sufficient decrease
+  sufficient: boolean; // the Armijo condition
+  slope?: number; // wolfe only: ∇L(θ + αd)·d
+  curvature?: boolean; // wolfe only: |slope| <= c2·|∇L(θ)·d|
+}
+
+export interface LineSearchResult {
+  method: string;
+  loss: number; // L(θ)
+  slope: number; // ∇L(θ)·d
+  trials: LineSearchTrial[];
+  step: number; // the accepted α
+  satisfied: boolean; // false when the trials ran out or d is not a descent direction
+}
+
 export interface DataGenConfig2D {
   num_points: number;
   x1_min: number;
@@ -64,6 +118,15 @@ export interface DataGenConfig2D {
   true_w2: number;
   noise_level: number;
   seed?: number;
+  correlated?: FeatureCorrelation; // bivariate normal features instead of the x ranges
+}
+
+export interface FeatureCorrelation {
+  correlation: number; // ρ in (-1, 1)
+  mean_x1: number;
+  mean_x2: number;
+  var_x1?: number; // default 1
+  var_x2?: number; // default 1
 }
 
 export interface LossGridPoint {
@@ -89,6 +152,25 @@ export interface LossGridConfig {
   resolution: number;
 }
 
+/**
+ * Dataset and result summary of a case run (Consistency2D in Go). Go and the
+ * browser draw different data from the same config, so their runs are compared
+ * by these statistics; config_hash comes from the case's config.json.
+ */
+export interface Consistency2D {
+  trainer: 'go' | 'browser';
+  config_hash: string;
+  num_points: number;
+  mean_x1: number;
+  mean_x2: number;
+  mean_y: number;
+  num_steps: number;
+  initial_loss: number;
+  final_loss: number;
+  final_w1: number;
+  final_w2: number;
+}
+
 // ============================================================================
 // Core Training Functions
 // ============================================================================
@@ -160,7 +242,8 @@ function computeLoss2D(data: DataPoint2D[], w1: number, w2: number): number {
 }
 
 /**
- * Train a 2-parameter linear model using gradient descent
+ * Train a 2-parameter linear model using gradient descent, or the second-order
+ * optimizer of config.optimizer (same as RunTraining in Go)
  * Returns snapshots of each training step
  */
 export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
@@ -168,12 +251,21 @@ export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
   let w1 = config.w1_init ?? 0;
   let w2 = config.w2_init ?? 0;
   const lr = config.lr;
+  const optimizer = config.optimizer ?? 'gd';
+  const hessian = hessian2D(config.data);
+  // BFGS state: the inverse-Hessian estimate, starting at lr·I, and the last step
+  let inverse: Matrix2 = [[lr, 0], [0, lr]];
+  let prev: { w: [number, number]; g: [number, number] } | null = null;
+  // Conjugate gradient state: the last gradient and direction
+  let prevG: [number, number] = [0, 0];
+  let prevD: [number, number] = [0, 0];
 
   for (let step = 0; step < config.max_steps; step++) {
     let totalLoss = 0;
     let totalGradW1 = 0;
     let totalGradW2 = 0;
     const pointDetails: PointSnapshot2D[] = [];
+    const gaussNewton: Matrix2 = [[0, 0], [0, 0]]; // Σ JᵢJᵢᵀ, Jᵢ = ∂(y_pred - y_true)/∂w = (x1, x2)
 
     // Compute per-point values
     for (const point of config.data) {
@@ -195,6 +287,9 @@ export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
       totalLoss += loss;
       totalGradW1 += gw1;
       totalGradW2 += gw2;
+      gaussNewton[0][0] += point.x1 * point.x1;
+      gaussNewton[0][1] += point.x1 * point.x2;
+      gaussNewton[1][1] += point.x2 * point.x2;
     }
 
     // Average over dataset
@@ -202,15 +297,97 @@ export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
     const avgLoss = totalLoss / n;
     const avgGradW1 = totalGradW1 / n;
     const avgGradW2 = totalGradW2 / n;
+    gaussNewton[0][0] *= 2 / n;
+    gaussNewton[0][1] *= 2 / n;
+    gaussNewton[1][1] *= 2 / n;
+    gaussNewton[1][0] = gaussNewton[0][1];
 
     const gradMag = gradientMagnitude(avgGradW1, avgGradW2);
     const gradDir = gradientDirection(avgGradW1, avgGradW2);
 
     // Compute updates
-    const deltaW1 = -lr * avgGradW1;
-    const deltaW2 = -lr * avgGradW2;
+    const grad: [number, number] = [avgGradW1, avgGradW2];
+    let direction: [number, number] = [-avgGradW1, -avgGradW2];
+    let stepLength = lr;
+    let beta = 0;
+    let coordinate: number | undefined;
+    let inverseHessian: Matrix2 | undefined;
+    const newtonStep = (m: Matrix2) => {
+      const d = newtonDirection2D(m, grad);
+      if (d) {
+        direction = d;
+        stepLength = 1;
+      }
+    };
+    if (optimizer === 'newton') {
+      newtonStep(hessian);
+    } else if (optimizer === 'gauss-newton') {
+      newtonStep(gaussNewton);
+    } else if (optimizer === 'damped-newton') {
+      const mu = config.damping ?? 0;
+      newtonStep([[hessian[0][0] + mu, hessian[0][1]], [hessian[1][0], hessian[1][1] + mu]]);
+    } else if (optimizer === 'bfgs') {
+      if (prev) {
+        inverse = updateBFGS(inverse, [w1 - prev.w[0], w2 - prev.w[1]], [grad[0] - prev.g[0], grad[1] - prev.g[1]]);
+      }
+      prev = { w: [w1, w2], g: grad };
+      direction = [-(inverse[0][0] * grad[0] + inverse[0][1] * grad[1]), -(inverse[1][0] * grad[0] + inverse[1][1] * grad[1])];
+      stepLength = 1;
+      inverseHessian = [[inverse[0][0], inverse[0][1]], [inverse[1][0], inverse[1][1]]];
+    } else if (optimizer === 'cg') {
+      // Fletcher-Reeves, restarting at -∇L every 2 steps or when not a descent direction
+      if (step % 2 !== 0) {
+        const prevNorm = prevG[0] * prevG[0] + prevG[1] * prevG[1];
+        beta = prevNorm > 0 ? (grad[0] * grad[0] + grad[1] * grad[1]) / prevNorm : 0;
+        const conjugate: [number, number] = [direction[0] + beta * prevD[0], direction[1] + beta * prevD[1]];
+        if (conjugate[0] * grad[0] + conjugate[1] * grad[1] < 0) {
+          direction = conjugate;
+        } else {
+          beta = 0;
+        }
+      }
+      prevG = grad;
+      prevD = direction;
+      stepLength = exactStep2D(grad, direction, hessian, lr);
+    } else if (optimizer === 'coordinate') {
+      // Cycle w1, w2, w1, ... and minimize exactly along the axis
+      const i = step % 2;
+      direction = i === 0 ? [-grad[0], 0] : [0, -grad[1]];
+      stepLength = exactStep2D(grad, direction, hessian, lr);
+      coordinate = i + 1;
+    }
+    let search: LineSearchResult | undefined;
+    const lineSearch = config.line_search;
+    if (lineSearch && lineSearch.method !== 'fixed') {
+      const d = direction;
+      const hd = [hessian[0][0] * d[0] + hessian[0][1] * d[1], hessian[1][0] * d[0] + hessian[1][1] * d[1]];
+      search = searchLine(
+        lineSearch,
+        {
+          loss: avgLoss,
+          slope: grad[0] * d[0] + grad[1] * d[1],
+          curvature: d[0] * hd[0] + d[1] * hd[1],
+          evaluate: (alpha) => {
+            const tw1 = w1 + alpha * d[0];
+            const tw2 = w2 + alpha * d[1];
+            let trialLoss = 0, tg1 = 0, tg2 = 0;
+            for (const p of config.data) {
+              trialLoss += pointLoss(forward(tw1, tw2, p.x1, p.x2), p.y_true) / n;
+              tg1 += gradW1(tw1, tw2, p.x1, p.x2, p.y_true) / n;
+              tg2 += gradW2(tw1, tw2, p.x1, p.x2, p.y_true) / n;
+            }
+            return [trialLoss, tg1 * d[0] + tg2 * d[1]];
+          }
+        },
+        stepLength
+      );
+      stepLength = search.step;
+    }
+    const deltaW1 = stepLength * direction[0];
+    const deltaW2 = stepLength * direction[1];
     const w1New = w1 + deltaW1;
     const w2New = w2 + deltaW2;
+    const curvature = optimizer === 'gauss-newton' ? gaussNewton : hessian;
 
     // Create snapshot
     snapshots.push({
@@ -223,6 +400,14 @@ export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
       gradient_magnitude: gradMag,
       gradient_direction: gradDir,
       point_details: pointDetails,
+      hessian: curvature,
+      newton_direction: newtonDirection2D(curvature, grad) ?? [0, 0],
+      ...(inverseHessian && { inverse_hessian: inverseHessian }),
+      search_direction: direction,
+      step_length: stepLength,
+      conjugacy_beta: beta,
+      ...(coordinate && { coordinate }),
+      ...(search && { line_search: search }),
       update_components: {
         w1_old: w1,
         w2_old: w2,
@@ -233,7 +418,14 @@ export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
         delta_w2: deltaW2,
         w1_new: w1New,
         w2_new: w2New
-      }
+      },
+      gradient_turn_angle: 0,
+      step_optimum_angle: 0,
+      path_efficiency: 1,
+      sign_flips_w1: 0,
+      sign_flips_w2: 0,
+      error_eigen1: 0,
+      error_eigen2: 0
     });
 
     // Update parameters
@@ -241,9 +433,259 @@ export function trainModel2D(config: TrainingConfig2D): LinearSnapshot[] {
     w2 = w2New;
   }
 
+  annotateTrajectory(snapshots, config.data);
+  annotateEigenErrors(snapshots, config.data);
   return snapshots;
 }
 
+/**
+ * Newton direction -m⁻¹g, or null when m is (nearly) singular (same as NewtonDirection in Go)
+ */
+export function newtonDirection2D(m: Matrix2, g: [number, number]): [number, number] | null {
+  const det = m[0][0] * m[1][1] - m[0][1] * m[1][0];
+  const scale = Math.max(Math.abs(m[0][0] * m[1][1]), Math.abs(m[0][1] * m[1][0]));
+  if (!(Math.abs(det) > 1e-12 * scale)) {
+    return null;
+  }
+  return [-(m[1][1] * g[0] - m[0][1] * g[1]) / det, -(m[0][0] * g[1] - m[1][0] * g[0]) / det];
+}
+
+/**
+ * Line search along d from θ (same as the Go linesearch package): armijo
+ * backtracks from the initial step, wolfe brackets and bisects until the strong
+ * Wolfe conditions hold, exact steps to the minimum of the quadratic
+ */
+function searchLine(
+  config: LineSearchConfig,
+  line: { loss: number; slope: number; curvature: number; evaluate: (alpha: number) => [number, number] },
+  initial: number
+): LineSearchResult {
+  const c1 = config.c1 || 1e-4;
+  const c2 = config.c2 || 0.9;
+  const shrink = config.shrink || 0.5;
+  const maxTrials = config.max_trials || 30;
+  const result: LineSearchResult = {
+    method: config.method,
+    loss: line.loss,
+    slope: line.slope,
+    trials: [],
+    step: 0,
+    satisfied: false
+  };
+  if (!(line.slope < 0)) {
+    // Already at a stationary point (slope 0) or not a descent direction
+    result.satisfied = line.slope === 0;
+    return result;
+  }
+  const tryStep = (alpha: number, wolfe: boolean): LineSearchTrial => {
+    const [loss, slope] = line.evaluate(alpha);
+    const bound = line.loss + c1 * alpha * line.slope;
+    const trial: LineSearchTrial = { step: alpha, loss, bound, sufficient: loss <= bound };
+    if (wolfe) {
+      trial.slope = slope;
+      trial.curvature = Math.abs(slope) <= c2 * Math.abs(line.slope);
+    }
+    result.trials.push(trial);
+    return trial;
+  };
+
+  if (config.method === 'exact') {
+    const alpha = line.curvature > 0 ? -line.slope / line.curvature : initial;
+    tryStep(alpha, false);
+    result.step = alpha;
+    result.satisfied = line.curvature > 0;
+  } else if (config.method === 'wolfe') {
+    let lo = 0;
+    let hi = Infinity;
+    let alpha = initial;
+    for (let i = 0; i < maxTrials; i++) {
+      const t = tryStep(alpha, true);
+      if (!t.sufficient || (t.slope! > 0 && !t.curvature)) {
+        hi = alpha; // too far: the loss rose or the slope turned steeply uphill
+      } else if (!t.curvature) {
+        lo = alpha; // too short: still steeply downhill
+      } else {
+        result.step = alpha;
+        result.satisfied = true;
+        return result;
+      }
+      alpha = hi === Infinity ? alpha * 2 : (lo + hi) / 2;
+    }
+    result.step = lo; // the longest step with sufficient decrease, if any
+  } else {
+    let alpha = initial;
+    for (let i = 0; i < maxTrials; i++) {
+      if (tryStep(alpha, false).sufficient) {
+        result.step = alpha;
+        result.satisfied = true;
+        return result;
+      }
+      alpha *= shrink;
+    }
+  }
+  return result;
+}
+
+/**
+ * Step length minimizing the quadratic loss along d, -gᵀd / dᵀHd, or fallback
+ * when the loss is not curved along d (same as the Go optimizer)
+ */
+function exactStep2D(g: [number, number], d: [number, number], h: Matrix2, fallback: number): number {
+  const slope = g[0] * d[0] + g[1] * d[1];
+  if (slope === 0) {
+    return 0;
+  }
+  const curvature = d[0] * (h[0][0] * d[0] + h[0][1] * d[1]) + d[1] * (h[1][0] * d[0] + h[1][1] * d[1]);
+  return curvature > 0 ? -slope / curvature : fallback;
+}
+
+/**
+ * BFGS update of the inverse-Hessian estimate b with step s and gradient change y:
+ * B ← (I - ρsyᵀ) B (I - ρysᵀ) + ρssᵀ with ρ = 1/yᵀs. Steps without positive
+ * curvature are skipped (same as the Go optimizer).
+ */
+function updateBFGS(b: Matrix2, s: [number, number], y: [number, number]): Matrix2 {
+  const ys = y[0] * s[0] + y[1] * s[1];
+  if (!(ys > 1e-12 * Math.hypot(y[0], y[1]) * Math.hypot(s[0], s[1]))) {
+    return b;
+  }
+  const rho = 1 / ys;
+  const left: Matrix2 = [
+    [1 - rho * s[0] * y[0], -rho * s[0] * y[1]],
+    [-rho * s[1] * y[0], 1 - rho * s[1] * y[1]]
+  ];
+  const next: Matrix2 = [[0, 0], [0, 0]];
+  for (let i = 0; i < 2; i++) {
+    for (let j = 0; j < 2; j++) {
+      for (let k = 0; k < 2; k++) {
+        for (let l = 0; l < 2; l++) {
+          next[i][j] += left[i][k] * b[k][l] * left[j][l];
+        }
+      }
+      next[i][j] += rho * s[i] * s[j];
+    }
+  }
+  return next;
+}
+
+/**
+ * Least-squares solution of y = w1*x1 + w2*x2 from the normal equations
+ * Returns null when the features are (nearly) collinear
+ */
+export function optimum2D(data: DataPoint2D[]): { w1: number; w2: number } | null {
+  let s11 = 0, s12 = 0, s22 = 0, s1y = 0, s2y = 0;
+  for (const p of data) {
+    s11 += p.x1 * p.x1;
+    s12 += p.x1 * p.x2;
+    s22 += p.x2 * p.x2;
+    s1y += p.x1 * p.y_true;
+    s2y += p.x2 * p.y_true;
+  }
+  const det = s11 * s22 - s12 * s12;
+  if (Math.abs(det) <= 1e-12 * Math.max(s11 * s22, 1e-300)) {
+    return null;
+  }
+  return { w1: (s22 * s1y - s12 * s2y) / det, w2: (s11 * s2y - s12 * s1y) / det };
+}
+
+/**
+ * Fill the trajectory metrics of each snapshot (same as AnnotateTrajectory in Go)
+ */
+function annotateTrajectory(snapshots: LinearSnapshot[], data: DataPoint2D[]): void {
+  const opt = optimum2D(data);
+  let pathLength = 0;
+  let flipsW1 = 0;
+  let flipsW2 = 0;
+
+  snapshots.forEach((s, i) => {
+    if (i > 0) {
+      const prev = snapshots[i - 1];
+      s.gradient_turn_angle = angleBetween(prev.grad_w1, prev.grad_w2, s.grad_w1, s.grad_w2);
+      pathLength += Math.hypot(s.w1 - prev.w1, s.w2 - prev.w2);
+      if (prev.grad_w1 * s.grad_w1 < 0) flipsW1++;
+      if (prev.grad_w2 * s.grad_w2 < 0) flipsW2++;
+    }
+    // The real update: only plain gradient descent moves along -∇L. At
+    // the optimum the direction to it is rounding noise, so the angle is 0.
+    const dist = opt ? Math.hypot(opt.w1 - s.w1, opt.w2 - s.w2) : 0;
+    if (opt && dist > 1e-9 * Math.max(1, Math.hypot(opt.w1, opt.w2))) {
+      s.step_optimum_angle = angleBetween(
+        s.update_components.delta_w1, s.update_components.delta_w2, opt.w1 - s.w1, opt.w2 - s.w2);
+    }
+    s.path_efficiency = 1;
+    if (pathLength > 0) {
+      s.path_efficiency = finite(Math.hypot(s.w1 - snapshots[0].w1, s.w2 - snapshots[0].w2) / pathLength);
+    }
+    s.sign_flips_w1 = flipsW1;
+    s.sign_flips_w2 = flipsW2;
+  });
+}
+
+/**
+ * Hessian of the MSE loss, 2/n XᵀX
+ */
+function hessian2D(data: DataPoint2D[]): Matrix2 {
+  let a = 0, b = 0, d = 0;
+  for (const p of data) {
+    a += (2 * p.x1 * p.x1) / data.length;
+    b += (2 * p.x1 * p.x2) / data.length;
+    d += (2 * p.x2 * p.x2) / data.length;
+  }
+  return [[a, b], [b, d]];
+}
+
+/**
+ * Eigenvectors of the MSE Hessian 2/n XᵀX, steepest first (same as AnalyzeHessian in Go)
+ */
+export function hessianEigenvectors2D(data: DataPoint2D[]): [[number, number], [number, number]] {
+  let a = 0, b = 0, d = 0;
+  for (const p of data) {
+    a += (2 * p.x1 * p.x1) / data.length;
+    b += (2 * p.x1 * p.x2) / data.length;
+    d += (2 * p.x2 * p.x2) / data.length;
+  }
+  const theta = Math.atan2(2 * b, a - d) / 2;
+  return [
+    [Math.cos(theta), Math.sin(theta)],
+    [-Math.sin(theta), Math.cos(theta)]
+  ];
+}
+
+/**
+ * Fill the parameter error of each snapshot in the eigenbasis of the Hessian
+ * (same as AnnotateEigenErrors in Go)
+ */
+function annotateEigenErrors(snapshots: LinearSnapshot[], data: DataPoint2D[]): void {
+  const opt = optimum2D(data);
+  if (!opt) {
+    return;
+  }
+  const [v1, v2] = hessianEigenvectors2D(data);
+  for (const s of snapshots) {
+    const e1 = s.w1 - opt.w1;
+    const e2 = s.w2 - opt.w2;
+    s.error_eigen1 = finite(v1[0] * e1 + v1[1] * e2);
+    s.error_eigen2 = finite(v2[0] * e1 + v2[1] * e2);
+  }
+}
+
+/**
+ * Angle between two vectors in [0, π], or 0 if either is zero or not finite
+ */
+function angleBetween(ax: number, ay: number, bx: number, by: number): number {
+  const na = Math.hypot(ax, ay);
+  const nb = Math.hypot(bx, by);
+  if (na === 0 || nb === 0) {
+    return 0;
+  }
+  const cos = (ax * bx + ay * by) / (na * nb);
+  return finite(Math.acos(Math.max(-1, Math.min(1, cos))));
+}
+
+function finite(v: number): number {
+  return Number.isFinite(v) ? v : 0;
+}
+
 // ============================================================================
 // Data Generation
 // ============================================================================
@@ -257,10 +699,14 @@ export function generateRandomData2D(config: DataGenConfig2D): DataPoint2D[] {
   if (config.num_points <= 0) {
     throw new Error(`num_points must be positive, got ${config.num_points}`);
   }
-  if (config.x1_max <= config.x1_min) {
+  const correlated = config.correlated;
+  if (correlated) {
+    if (!(correlated.correlation > -1 && correlated.correlation < 1)) {
+      throw new Error(`correlation must be in (-1, 1), got ${correlated.correlation}`);
+    }
+  } else if (config.x1_max <= config.x1_min) {
     throw new Error('x1_max must be greater than x1_min');
-  }
-  if (config.x2_max <= config.x2_min) {
+  } else if (config.x2_max <= config.x2_min) {
     throw new Error('x2_max must be greater than x2_min');
   }
   if (config.noise_level < 0) {
@@ -275,9 +721,10 @@ export function generateRandomData2D(config: DataGenConfig2D): DataPoint2D[] {
   const x2Range = config.x2_max - config.x2_min;
 
   for (let i = 0; i < config.num_points; i++) {
-    // Generate random x1, x2 in specified ranges
-    const x1 = config.x1_min + rng() * x1Range;
-    const x2 = config.x2_min + rng() * x2Range;
+    // Generate random x1, x2 in specified ranges, or correlated
+    const [x1, x2] = correlated
+      ? sampleCorrelated(correlated, rng)
+      : [config.x1_min + rng() * x1Range, config.x2_min + rng() * x2Range];
 
     // Compute true y value
     const yTrue = config.true_w1 * x1 + config.true_w2 * x2;
@@ -292,6 +739,21 @@ export function generateRandomData2D(config: DataGenConfig2D): DataPoint2D[] {
   return data;
 }
 
+/**
+ * Draw (x1, x2) from a bivariate normal: x1 = μ1 + σ1 z1 and
+ * x2 = μ2 + σ2 (ρ z1 + √(1-ρ²) z2), with z1, z2 from Box-Muller
+ */
+function sampleCorrelated(c: FeatureCorrelation, rng: () => number): [number, number] {
+  const radius = Math.sqrt(-2 * Math.log(1 - rng()));
+  const angle = 2 * Math.PI * rng();
+  const z1 = radius * Math.cos(angle);
+  const z2 = radius * Math.sin(angle);
+  const s1 = Math.sqrt(c.var_x1 || 1);
+  const s2 = Math.sqrt(c.var_x2 || 1);
+  const rho = c.correlation;
+  return [c.mean_x1 + s1 * z1, c.mean_x2 + s2 * (rho * z1 + Math.sqrt(1 - rho * rho) * z2)];
+}
+
 /**
  * Simple seeded random number generator for reproducibility
  * Uses linear congruential generator (LCG)
@@ -365,3 +827,44 @@ export function computeLossGrid(data: DataPoint2D[], config: LossGridConfig): Lo
     points
   };
 }
+
+// ============================================================================
+// Go Comparison
+// ============================================================================
+
+/**
+ * Summarize a browser run in the format of the consistency metadata of the
+ * Go-trained snapshots.json written by `generate --phase2-snapshots`
+ */
+export function summarizeConsistency2D(
+  configHash: string,
+  data: DataPoint2D[],
+  snapshots: LinearSnapshot[]
+): Consistency2D {
+  const c: Consistency2D = {
+    trainer: 'browser',
+    config_hash: configHash,
+    num_points: data.length,
+    mean_x1: 0,
+    mean_x2: 0,
+    mean_y: 0,
+    num_steps: snapshots.length,
+    initial_loss: 0,
+    final_loss: 0,
+    final_w1: 0,
+    final_w2: 0
+  };
+  for (const p of data) {
+    c.mean_x1 += p.x1 / data.length;
+    c.mean_x2 += p.x2 / data.length;
+    c.mean_y += p.y_true / data.length;
+  }
+  if (snapshots.length > 0) {
+    const last = snapshots[snapshots.length - 1];
+    c.initial_loss = snapshots[0].loss;
+    c.final_loss = last.loss;
+    c.final_w1 = last.update_components.w1_new;
+    c.final_w2 = last.update_components.w2_new;
+  }
+  return c;
+}
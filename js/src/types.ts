@@ -20,7 +20,34 @@ export interface Snapshot {
   grad_w: number;
   loss: number;
   point_details: PointSnapshot[];
-  update_components: UpdateDetails;
+  update_components: UpdateDetails; // lr is the step accepted by the line search, if any
+  line_search?: LineSearchResult; // omitted when stepping by the learning rate
+}
+
+export interface LineSearchConfig {
+  method: 'fixed' | 'armijo' | 'wolfe' | 'exact';
+  c1?: number;
+  c2?: number;
+  shrink?: number;
+  max_trials?: number;
+}
+
+export interface LineSearchTrial {
+  step: number; // α
+  loss: number; // L(θ + αd)
+  bound: number; // L(θ) + c1·α·slope, the largest loss with sufficient decrease
+  sufficient: boolean; // the Armijo condition
+  slope?: number; // wolfe only: ∇L(θ + αd)·d
+  curvature?: boolean; // wolfe only: |slope| <= c2·|∇L(θ)·d|
+}
+
+export interface LineSearchResult {
+  method: string;
+  loss: number; // L(θ)
+  slope: number; // ∇L(θ)·d
+  trials: LineSearchTrial[];
+  step: number; // the accepted α
+  satisfied: boolean; // false when the trials ran out or d is not a descent direction
 }
 
 export type LayoutMode = '1col' | '2col' | '3col' | 'auto';
@@ -1,75 +1,58 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"log"
-
-	core "github.com/iOliverNguyen/ml-viz/go"
-	"github.com/iOliverNguyen/ml-viz/go/linear"
-	"github.com/iOliverNguyen/ml-viz/go/neuron"
+	"os"
 )
 
-func main() {
-	server := flag.Bool("server", false, "Start HTTP server after training")
-	flag.BoolVar(server, "s", false, "Start HTTP server after training (shorthand)")
-	generateCases := flag.Bool("generate-cases", false, "Generate pre-computed Phase 1 training cases")
-	generateCases2 := flag.Bool("generate-cases-phase2", false, "Generate pre-computed Phase 2 training cases")
-	generateCases3 := flag.Bool("generate-cases-phase3", false, "Generate pre-computed Phase 3 training cases")
-	flag.Parse()
+// command is one subcommand of the CLI
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	// Check if generate-cases-phase3 command was requested
-	if *generateCases3 {
-		fmt.Println("Generating Phase 3 pre-computed training cases...")
-		outputDir := "js/public/cases-phase3"
-		if err := neuron.GenerateAllCases(outputDir); err != nil {
-			log.Fatalf("Failed to generate Phase 3 cases: %v", err)
-		}
-		fmt.Println("✓ Phase 3 cases generated successfully!")
-		return
-	}
+var commands = []command{
+	{"train", "Train one run and write its snapshots", runTrain},
+	{"generate", "Generate the pre-computed case libraries", runGenerate},
+	{"serve", "Start the HTTP API (and the embedded frontend, if built in)", runServe},
+	{"sweep", "Train once per value of one training parameter and compare", runSweep},
+	{"ensemble", "Train one config across seeds and summarize the spread of the runs", runEnsemble},
+	{"inspect", "Summarize a snapshots file, run or case", runInspect},
+	{"export", "Export a snapshots file to steps and points tables", runExport},
+}
 
-	// Check if generate-cases-phase2 command was requested
-	if *generateCases2 {
-		fmt.Println("Generating Phase 2 pre-computed training cases...")
-		outputDir := "js/public/cases-phase2"
-		if err := linear.GenerateCases2D(outputDir); err != nil {
-			log.Fatalf("Failed to generate Phase 2 cases: %v", err)
-		}
-		fmt.Println("✓ Phase 2 cases generated successfully!")
-		return
-	}
+func main() {
+	log.SetFlags(0)
 
-	// Check if generate-cases command was requested
-	if *generateCases {
-		fmt.Println("Generating Phase 1 pre-computed training cases...")
-		if err := core.GenerateCases(); err != nil {
-			log.Fatalf("Failed to generate cases: %v", err)
-		}
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
 		return
 	}
 
-	// Run training and get snapshots
-	fmt.Println("Running training...")
-	snapshots := core.RunTraining()
-
-	// Write to output/ directory
-	filepath := "output/snapshots.json"
-	if err := core.WriteSnapshots(snapshots, filepath); err != nil {
-		log.Fatalf("Failed to write snapshots: %v", err)
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				log.Fatalf("%s: %v", cmd.name, err)
+			}
+			return
+		}
 	}
 
-	fmt.Printf("\nSnapshots written to %s (%d steps)\n", filepath, len(snapshots))
-	fmt.Println("\nVisualization options:")
-	fmt.Println("  1. Server mode: go run . --server")
-	fmt.Println("  2. Batch mode:  cp output/snapshots.json js/public/")
-	fmt.Println()
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
 
-	// Start server if requested
-	if *server {
-		fmt.Println("Starting HTTP server...")
-		if err := core.StartServer(":5050"); err != nil {
-			log.Fatalf("Server failed: %v", err)
-		}
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: ml-viz <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Every command accepts --config (JSON or YAML), --phase, --output, --addr, --quiet and --verbose.")
+	fmt.Fprintln(os.Stderr, "Run 'ml-viz <command> -h' for the flags of one command.")
 }
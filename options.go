@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	core "github.com/iOliverNguyen/ml-viz/go"
+	"github.com/iOliverNguyen/ml-viz/go/ensemble"
+	"github.com/iOliverNguyen/ml-viz/go/linear"
+	"github.com/iOliverNguyen/ml-viz/go/neuron"
+	"gopkg.in/yaml.v3"
+)
+
+// Options holds the settings of every subcommand. They are read from an
+// optional JSON or YAML config file and overridden by command-line flags.
+type Options struct {
+	Phase    int    `json:"phase"`     // 1, 2 or 3 (0 = all, for generate)
+	Output   string `json:"output"`    // output file or directory, depending on the command
+	Addr     string `json:"addr"`      // listen address of serve
+	Quiet    bool   `json:"quiet"`     // print nothing but errors and requested results
+	Verbose  bool   `json:"verbose"`   // also print configs and per-step details
+	CacheDir string `json:"cache_dir"` // result cache directory ("" = memory only)
+	CasesDir string `json:"cases_dir"` // extra case files in phase1/, phase2/ and phase3/ subdirectories
+
+	// Training (train, sweep, ensemble). Data, dataset, training, init params and loss grid
+	// use the same JSON as the phase's API requests and override the case's values.
+	Case       string          `json:"case"`        // start from a case of the phase
+	Data       json.RawMessage `json:"data"`        // data generation config
+	Dataset    json.RawMessage `json:"dataset"`     // custom dataset, used instead of data
+	Training   json.RawMessage `json:"training"`    // training config
+	InitParams json.RawMessage `json:"init_params"` // Phase 3 initial parameters
+	LossGrid   json.RawMessage `json:"loss_grid"`   // Phase 2 loss grid
+
+	Sweep    SweepOptions    `json:"sweep"`
+	Ensemble ensemble.Config `json:"ensemble"`
+	Generate GenerateOptions `json:"generate"`
+	Input    string          `json:"input"`  // snapshots file read by inspect and export
+	Format   string          `json:"format"` // export format: csv or columnar
+	Server   ServerOptions   `json:"server"`
+}
+
+// SweepOptions selects the training parameter a sweep varies
+type SweepOptions struct {
+	Param  string        `json:"param"`  // JSON name in the phase's training config, e.g. "lr"
+	Values []interface{} `json:"values"` // values to try
+}
+
+// GenerateOptions selects the optional outputs of generate
+type GenerateOptions struct {
+	Phase2Snapshots bool `json:"phase2_snapshots"` // also write Go-trained snapshots and loss grids of Phase 2 cases
+}
+
+// ServerOptions configures serve
+type ServerOptions struct {
+	MaxRuns        int      `json:"max_runs"`
+	RunTTL         Duration `json:"run_ttl"`
+	MaxPoints      int      `json:"max_points"`
+	MaxSteps       int      `json:"max_steps"`
+	MaxBodyBytes   int64    `json:"max_body_bytes"`
+	RequestTimeout Duration `json:"request_timeout"`
+	StaticDir      string   `json:"static_dir"`
+}
+
+// Duration is a time.Duration written as "30s" or "1h" in config files
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return json.Unmarshal(data, &d.Duration)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// defaultOptions returns the options used when neither a config file nor a flag sets them
+func defaultOptions() Options {
+	limits := core.DefaultLimits()
+	retention := core.DefaultRunRetention()
+	return Options{
+		Phase:  1,
+		Addr:   ":5050",
+		Format: core.FormatCSV,
+		Server: ServerOptions{
+			MaxRuns:        retention.MaxRuns,
+			RunTTL:         Duration{retention.TTL},
+			MaxPoints:      limits.MaxPoints,
+			MaxSteps:       limits.MaxSteps,
+			MaxBodyBytes:   limits.MaxBodyBytes,
+			RequestTimeout: Duration{limits.RequestTimeout},
+		},
+	}
+}
+
+// newFlagSet creates the flag set of a command with the flags every command shares
+func newFlagSet(name string, opts *Options, configPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(configPath, "config", "", "JSON or YAML config file; flags override its values")
+	fs.IntVar(&opts.Phase, "phase", opts.Phase, "Phase: 1 (scalar), 2 (two weights) or 3 (single neuron)")
+	fs.StringVar(&opts.Output, "output", opts.Output, "Output path")
+	fs.StringVar(&opts.Output, "o", opts.Output, "Output path (shorthand)")
+	fs.StringVar(&opts.Addr, "addr", opts.Addr, "Listen address")
+	fs.BoolVar(&opts.Quiet, "quiet", opts.Quiet, "Print only errors and requested results")
+	fs.BoolVar(&opts.Quiet, "q", opts.Quiet, "Print only errors and requested results (shorthand)")
+	fs.BoolVar(&opts.Verbose, "verbose", opts.Verbose, "Print configs and per-step details")
+	fs.BoolVar(&opts.Verbose, "v", opts.Verbose, "Print configs and per-step details (shorthand)")
+	fs.StringVar(&opts.CacheDir, "cache-dir", opts.CacheDir, "Directory for cached training results (default: memory only)")
+	fs.StringVar(&opts.CasesDir, "cases-dir", opts.CasesDir, "Directory with extra case files in phase1/, phase2/ and phase3/ (same ID replaces a built-in case)")
+	return fs
+}
+
+// parseOptions parses args, loads the config file they name and parses args
+// again so flags take precedence over the file. Flags may follow positional
+// arguments, which are returned.
+func parseOptions(fs *flag.FlagSet, args []string, opts *Options, configPath *string) ([]string, error) {
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if *configPath != "" {
+		if err := loadConfig(*configPath, opts); err != nil {
+			return nil, err
+		}
+		if _, err := parseInterspersed(fs, args); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Quiet && opts.Verbose {
+		return nil, fmt.Errorf("--quiet and --verbose are mutually exclusive")
+	}
+	setupOutput(*opts)
+	if opts.CasesDir != "" {
+		if err := addCaseFiles(opts.CasesDir); err != nil {
+			return nil, err
+		}
+	}
+	return positional, nil
+}
+
+// addCaseFiles adds the case files of dir/phase1, dir/phase2 and dir/phase3 to the case libraries
+func addCaseFiles(dir string) error {
+	if err := core.AddCaseFiles(filepath.Join(dir, "phase1")); err != nil {
+		return fmt.Errorf("failed to load Phase 1 cases: %w", err)
+	}
+	if err := linear.AddCaseFiles2D(filepath.Join(dir, "phase2")); err != nil {
+		return fmt.Errorf("failed to load Phase 2 cases: %w", err)
+	}
+	if err := neuron.AddCaseFiles(filepath.Join(dir, "phase3")); err != nil {
+		return fmt.Errorf("failed to load Phase 3 cases: %w", err)
+	}
+	return nil
+}
+
+// parseInterspersed parses flags that may appear before and after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// loadConfig overlays the settings of a JSON or YAML file onto opts
+func loadConfig(path string, opts *Options) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// YAML is converted to JSON so both formats share the json tags of every config type
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("invalid config %s: %w", path, err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(opts); err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return nil
+}
+
+// stdout receives requested results (inspect summaries, sweep tables) even in quiet mode
+var stdout io.Writer = os.Stdout
+
+// verbose enables debugJSON and per-step output
+var verbose bool
+
+// setupOutput applies quiet and verbose mode. Quiet mode silences progress
+// messages, including those printed by the training packages.
+func setupOutput(opts Options) {
+	verbose = opts.Verbose
+	if opts.Quiet {
+		if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+			os.Stdout = devNull
+		}
+	}
+}
+
+// debugJSON prints a labelled value as indented JSON in verbose mode
+func debugJSON(label string, v interface{}) {
+	if !verbose {
+		return
+	}
+	data, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Printf("%s:\n  %s\n", label, data)
+}
+
+// writeJSONFile writes v as indented JSON to path ("-" = stdout), creating its directory
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}